@@ -1,11 +1,27 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/droid/go-mcp/internal/command"
 	"github.com/droid/go-mcp/internal/document"
+	"github.com/droid/go-mcp/internal/gateway"
+	"github.com/droid/go-mcp/internal/jobs"
+	"github.com/droid/go-mcp/internal/openapi"
+	"github.com/droid/go-mcp/internal/pipeline"
+	"github.com/droid/go-mcp/internal/prompts"
+	"github.com/droid/go-mcp/internal/resources"
 	"github.com/droid/go-mcp/internal/search"
 	"github.com/droid/go-mcp/internal/server"
 	"github.com/droid/go-mcp/internal/tools"
@@ -14,23 +30,178 @@ import (
 func main() {
 	// 命令行参数
 	port := flag.String("port", "8080", "HTTP server port")
+	transport := flag.String("transport", "http", "Transport to serve: http or stdio")
+	maxConcurrency := flag.Int("max-concurrency", server.DefaultMaxConcurrency, "Maximum concurrent tool calls per client")
+	toolConcurrency := flag.String("tool-concurrency", "", "Per-tool concurrency limits per client, e.g. document=2,search=4")
+	promptsDir := flag.String("prompts", "", "Directory of JSON prompt templates to load")
+	commandTools := flag.String("command-tools", "", "JSON file declaring external command tools, reloaded on SIGHUP")
+	openapiSpec := flag.String("openapi", "", "JSON OpenAPI 3 document whose operations are registered as tools")
+	openapiBaseURL := flag.String("openapi-base-url", "", "Base URL for OpenAPI tools (defaults to the document's first server)")
+	openapiPrefix := flag.String("openapi-prefix", "", "Name prefix for OpenAPI tools")
+	var openapiHeaders headerFlags
+	flag.Var(&openapiHeaders, "openapi-header", "Header sent with every OpenAPI request, e.g. \"Authorization: Bearer $API_TOKEN\" (repeatable, environment variables are expanded)")
+	gatewayConfig := flag.String("gateway", "", "JSON file listing downstream MCP servers whose tools are proxied")
+	pipelinesDir := flag.String("pipelines", "", "Directory of JSON pipeline definitions to load")
+	debug := flag.Bool("debug", false, "Validate tool results against their output schemas")
+	toolTimeout := flag.Duration("tool-timeout", tools.DefaultTimeout, "Default tool execution timeout (0 for none)")
+	maxToolTimeout := flag.Duration("max-tool-timeout", tools.DefaultMaxTimeout, "Upper bound for any tool timeout, including per-call overrides (0 for none)")
+	toolTimeouts := flag.String("tool-timeouts", "", "Per-tool execution timeouts, e.g. document=10s,search=2s")
+	panicThreshold := flag.Int("panic-threshold", tools.DefaultPanicThreshold, "Disable a tool after this many consecutive panics (0 never disables)")
+	batchParallelism := flag.Int("batch-parallelism", server.DefaultBatchParallelism, "Maximum concurrently executed items per batch request")
+	jobTTL := flag.Duration("job-ttl", jobs.DefaultTTL, "How long finished asynchronous jobs are kept")
+	sessionTTL := flag.Duration("session-ttl", server.DefaultSessionTTL, "Expire idle Streamable HTTP sessions after this long (0 for never)")
+	allowedOrigins := flag.String("allowed-origins", "", "Comma-separated origins allowed to use the Streamable HTTP endpoint, * for any (defaults to localhost origins)")
 	flag.Parse()
 
-	// 创建工具管理器
-	toolMgr := tools.NewToolManager()
+	// stdio模式下标准输出只用于协议消息，日志统一写入标准错误
+	log.SetOutput(os.Stderr)
+
+	if *transport != "http" && *transport != "stdio" {
+		log.Fatalf("Unknown transport: %s", *transport)
+	}
+
+	// 创建工具管理器，记录每次工具调用
+	toolOpts := []tools.ManagerOption{
+		tools.WithPanicThreshold(*panicThreshold),
+		tools.WithDefaultTimeout(*toolTimeout),
+		tools.WithMaxTimeout(*maxToolTimeout),
+	}
+	timeouts, err := parseToolTimeouts(*toolTimeouts)
+	if err != nil {
+		log.Fatal("Invalid -tool-timeouts: ", err)
+	}
+	for tool, timeout := range timeouts {
+		toolOpts = append(toolOpts, tools.WithToolTimeout(tool, timeout))
+	}
+
+	toolMgr := tools.NewToolManager(toolOpts...)
+	toolMgr.Use(tools.Logging())
 
 	// 注册MCP工具
-	toolMgr.RegisterTool(search.NewSearchTool())
-	toolMgr.RegisterTool(document.NewDocumentTool())
+	searchTool := search.NewSearchTool()
+	documentTool := document.NewDocumentTool()
+	toolMgr.RegisterTool(searchTool)
+	toolMgr.RegisterTool(documentTool)
+
+	// 注册配置文件中的外部命令工具，收到SIGHUP时重新加载
+	if *commandTools != "" {
+		loader := command.NewLoader(toolMgr, *commandTools)
+		if err := loader.Load(); err != nil {
+			log.Fatal("Failed to load command tools: ", err)
+		}
+		go reloadOnSignal(loader)
+	}
+
+	// 将OpenAPI文档中的操作注册为工具
+	if *openapiSpec != "" {
+		openapiOpts := []openapi.Option{openapi.WithPrefix(*openapiPrefix)}
+		if *openapiBaseURL != "" {
+			openapiOpts = append(openapiOpts, openapi.WithBaseURL(*openapiBaseURL))
+		}
+		for _, header := range openapiHeaders {
+			openapiOpts = append(openapiOpts, openapi.WithHeader(header.key, header.value))
+		}
+		if _, err := openapi.Register(toolMgr, *openapiSpec, openapiOpts...); err != nil {
+			log.Fatal("Failed to import OpenAPI document: ", err)
+		}
+	}
+
+	// 注册内置管道并加载管道定义文件
+	for _, def := range builtinPipelines {
+		if err := pipeline.Register(toolMgr, def); err != nil {
+			log.Fatal("Invalid builtin pipeline: ", err)
+		}
+	}
+	if *pipelinesDir != "" {
+		if err := pipeline.LoadDir(toolMgr, *pipelinesDir); err != nil {
+			log.Fatal("Failed to load pipelines: ", err)
+		}
+	}
+
+	// 连接下游MCP服务器并代理其工具，下游工具在后台导入，与本地工具同名时被跳过
+	var gw *gateway.Gateway
+	if *gatewayConfig != "" {
+		gw = gateway.New(toolMgr)
+		if err := gw.LoadFile(*gatewayConfig); err != nil {
+			log.Fatal("Failed to load gateway config: ", err)
+		}
+		defer gw.Close()
+	}
+
+	// 发布文档和知识库资源
+	resMgr := resources.NewManager()
+	resMgr.RegisterProvider(documentTool)
+	resMgr.RegisterProvider(searchTool)
+
+	// 注册内置提示词并加载提示词模板文件
+	promptRegistry := prompts.NewRegistry(resMgr)
+	for _, tmpl := range builtinPrompts {
+		if err := promptRegistry.RegisterTemplate(tmpl); err != nil {
+			log.Fatal("Invalid builtin prompt: ", err)
+		}
+	}
+	if *promptsDir != "" {
+		if err := promptRegistry.LoadDir(*promptsDir); err != nil {
+			log.Fatal("Failed to load prompts: ", err)
+		}
+	}
 
 	// 创建并运行MCP服务器
-	mcpServer := server.NewMCPServer(toolMgr)
+	opts := []server.Option{
+		server.WithMaxConcurrency(*maxConcurrency),
+		server.WithResources(resMgr),
+		server.WithPrompts(promptRegistry),
+		server.WithDebug(*debug),
+		server.WithJobTTL(*jobTTL),
+		server.WithSessionTTL(*sessionTTL),
+		server.WithBatchParallelism(*batchParallelism),
+	}
+	limits, err := parseToolConcurrency(*toolConcurrency)
+	if err != nil {
+		log.Fatal("Invalid -tool-concurrency: ", err)
+	}
+	for tool, limit := range limits {
+		opts = append(opts, server.WithToolConcurrency(tool, limit))
+	}
+	for _, origin := range strings.Split(*allowedOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			opts = append(opts, server.WithAllowedOrigins(origin))
+		}
+	}
+
+	mcpServer := server.NewMCPServer(toolMgr, opts...)
 	go mcpServer.Run()
 
+	// 收到中断或终止信号时正常退出，执行defer断开下游服务器
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	if *transport == "stdio" {
+		log.Printf("MCP Server serving on stdio")
+		served := make(chan error, 1)
+		go func() {
+			served <- mcpServer.ServeStdio(os.Stdin, os.Stdout)
+		}()
+
+		select {
+		case err := <-served:
+			if err != nil {
+				log.Fatal("ServeStdio: ", err)
+			}
+		case sig := <-signals:
+			log.Printf("Received %v, shutting down", sig)
+		}
+		return
+	}
+
 	// 设置HTTP路由
 	http.HandleFunc("/ws", mcpServer.HandleWebSocket)
+	http.HandleFunc("/mcp", mcpServer.HandleStreamableHTTP)
 	http.HandleFunc("/tool", mcpServer.HandleToolRequest)
 	http.HandleFunc("/tools", mcpServer.GetAvailableTools)
+	http.HandleFunc("/metrics", mcpServer.HandleMetrics)
+	http.HandleFunc("/jobs", mcpServer.HandleJobs)
+	http.HandleFunc("/jobs/", mcpServer.HandleJobs)
 
 	// 健康检查
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -47,34 +218,157 @@ func main() {
 		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{
-			"name": "Go MCP Server",
-			"description": "一个Model Context Protocol服务器实现",
-			"version": "1.0.0",
-			"endpoints": {
-				"/ws": "WebSocket连接",
-				"/tool": "REST API工具请求",
-				"/tools": "获取可用工具列表",
-				"/health": "健康检查"
-			}
-		}`))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"name":        server.ServerName,
+			"description": server.ServerDescription,
+			"version":     server.ServerVersion,
+			"protocol":    server.LatestProtocolVersion,
+			"endpoints": map[string]string{
+				"/ws":      "WebSocket连接",
+				"/mcp":     "Streamable HTTP连接",
+				"/tool":    "REST API工具请求",
+				"/tools":   "获取可用工具列表",
+				"/metrics": "工具调用统计",
+				"/jobs":    "异步任务查询和取消",
+				"/health":  "健康检查",
+			},
+		})
 	})
 
 	// 启动HTTP服务器
 	serverAddr := ":" + *port
 	log.Printf("MCP Server starting on http://localhost%s", serverAddr)
 	log.Printf("Available endpoints:")
-	log.Printf("  - ws:     http://localhost%s/ws", serverAddr)
-	log.Printf("  - tool:   http://localhost%s/tool", serverAddr)
-	log.Printf("  - tools:  http://localhost%s/tools", serverAddr)
-	log.Printf("  - health: http://localhost%s/health", serverAddr)
+	log.Printf("  - ws:      http://localhost%s/ws", serverAddr)
+	log.Printf("  - mcp:     http://localhost%s/mcp", serverAddr)
+	log.Printf("  - tool:    http://localhost%s/tool", serverAddr)
+	log.Printf("  - tools:   http://localhost%s/tools", serverAddr)
+	log.Printf("  - metrics: http://localhost%s/metrics", serverAddr)
+	log.Printf("  - jobs:    http://localhost%s/jobs", serverAddr)
+	log.Printf("  - health:  http://localhost%s/health", serverAddr)
 	log.Printf("Available tools: %d", len(toolMgr.GetToolsSchema()))
 
 	for _, tool := range toolMgr.GetToolsSchema() {
 		log.Printf("  - %s: %s", tool["name"], tool["description"])
 	}
 
-	if err := http.ListenAndServe(serverAddr, nil); err != nil {
+	httpServer := &http.Server{Addr: serverAddr}
+	stopped := make(chan struct{})
+	go shutdownOnSignal(signals, httpServer, gw, stopped)
+
+	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
 		log.Fatal("ListenAndServe: ", err)
 	}
+	<-stopped
+}
+
+// reloadOnSignal 每次收到SIGHUP时重新加载外部命令工具
+func reloadOnSignal(loader *command.Loader) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	for range signals {
+		if err := loader.Load(); err != nil {
+			log.Printf("Failed to reload command tools, keeping current tools: %v", err)
+		}
+	}
+}
+
+// shutdownTimeout 关闭HTTP服务器时等待处理中的请求结束的最长时间
+const shutdownTimeout = 10 * time.Second
+
+// shutdownOnSignal 收到中断或终止信号时先断开所有下游服务器（以stdio连接的下游子进程随之结束），
+// 再关闭HTTP服务器并等待处理中的请求结束，完成后关闭stopped
+func shutdownOnSignal(signals <-chan os.Signal, httpServer *http.Server, gw *gateway.Gateway, stopped chan<- struct{}) {
+	defer close(stopped)
+
+	sig := <-signals
+	log.Printf("Received %v, shutting down", sig)
+	if gw != nil {
+		gw.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Printf("HTTP server shutdown: %v", err)
+		httpServer.Close()
+	}
+}
+
+// header 一个请求头
+type header struct {
+	key   string
+	value string
+}
+
+// headerFlags 可重复的 "Key: Value" 请求头参数，值中的环境变量被展开
+type headerFlags []header
+
+// String 实现flag.Value接口
+func (h *headerFlags) String() string {
+	keys := make([]string, 0, len(*h))
+	for _, item := range *h {
+		keys = append(keys, item.key)
+	}
+	return strings.Join(keys, ",")
+}
+
+// Set 实现flag.Value接口
+func (h *headerFlags) Set(value string) error {
+	key, val, found := strings.Cut(value, ":")
+	if !found || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("header must be in the form \"Key: Value\"")
+	}
+	*h = append(*h, header{
+		key:   strings.TrimSpace(key),
+		value: os.ExpandEnv(strings.TrimSpace(val)),
+	})
+	return nil
+}
+
+// parseToolConcurrency 解析形如 document=2,search=4 的工具并发上限配置
+func parseToolConcurrency(value string) (map[string]int, error) {
+	limits := make(map[string]int)
+	if value == "" {
+		return limits, nil
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		name, limit, found := strings.Cut(strings.TrimSpace(item), "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("expected tool=limit, got %q", item)
+		}
+
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid limit for tool %s: %q", name, limit)
+		}
+		limits[name] = n
+	}
+
+	return limits, nil
+}
+
+// parseToolTimeouts 解析形如 document=10s,search=2s 的工具超时配置
+func parseToolTimeouts(value string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	if value == "" {
+		return timeouts, nil
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		name, timeout, found := strings.Cut(strings.TrimSpace(item), "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("expected tool=duration, got %q", item)
+		}
+
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid timeout for tool %s: %q", name, timeout)
+		}
+		timeouts[name] = d
+	}
+
+	return timeouts, nil
 }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/droid/go-mcp/internal/pipeline"
+)
+
+// builtinPipelines 服务器内置的管道工具
+var builtinPipelines = []pipeline.Definition{
+	{
+		Name:        "search_and_summarize",
+		Description: "搜索知识库并总结排名最高的结果",
+		Inputs: []pipeline.Input{
+			{Name: "query", Description: "搜索查询", Required: true},
+			{
+				Name:        "format",
+				Description: "摘要的格式",
+				Default:     "bullet_points",
+				Enum:        []interface{}{"text", "bullet_points", "json"},
+			},
+		},
+		Steps: []pipeline.Step{
+			{
+				ID:     "search",
+				Tool:   "search",
+				Params: map[string]interface{}{"query": "$.inputs.query", "max_results": 1},
+			},
+			{
+				ID:   "summarize",
+				Tool: "document",
+				Params: map[string]interface{}{
+					"action":  "summarize",
+					"content": "$.steps.search.results[0].content",
+					"format":  "$.inputs.format",
+				},
+			},
+		},
+	},
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/droid/go-mcp/internal/prompts"
+)
+
+// builtinPrompts 服务器内置的提示词模板
+var builtinPrompts = []prompts.Template{
+	{
+		Name:        "summarize_document",
+		Description: "将指定文档总结为文本或要点列表",
+		Arguments: []prompts.Argument{
+			{Name: "document_id", Description: "要总结的文档ID，如 doc-1", Required: true},
+			{Name: "format", Description: "摘要格式：text 或 bullet_points，默认 text"},
+		},
+		Messages: []prompts.MessageTemplate{
+			{
+				Role: prompts.RoleUser,
+				Text: "请总结下面的文档{{if eq .format \"bullet_points\"}}，并以要点列表的形式输出{{end}}。",
+			},
+			{
+				Role:     prompts.RoleUser,
+				Resource: "doc://{{.document_id}}",
+			},
+		},
+	},
+	{
+		Name:        "search_and_cite",
+		Description: "先搜索知识库，再基于搜索结果回答并注明出处",
+		Arguments: []prompts.Argument{
+			{Name: "query", Description: "要回答的问题", Required: true},
+		},
+		Messages: []prompts.MessageTemplate{
+			{
+				Role: prompts.RoleUser,
+				Text: "请使用 search 工具搜索「{{.query}}」，然后仅基于搜索结果回答这个问题。" +
+					"回答中的每个论点都要以 [标题](URL) 的形式注明来源；如果搜索结果不足以回答，请直接说明。",
+			},
+		},
+	},
+}
@@ -0,0 +1,299 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/droid/go-mcp/internal/schema"
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// 标准输出的解析方式
+const (
+	// OutputAuto 标准输出是合法JSON时按JSON解析，否则作为文本（默认）
+	OutputAuto = "auto"
+	// OutputJSON 标准输出必须是合法JSON
+	OutputJSON = "json"
+	// OutputText 标准输出作为文本
+	OutputText = "text"
+)
+
+// maxStderr 错误信息中保留的标准错误输出的最大字节数
+const maxStderr = 4096
+
+// Config 外部命令工具的声明式配置。Args、Env的值和Dir是模板，通过 {{.参数名}}
+// 引用工具参数，{{json .参数名}} 输出参数的JSON；Args中渲染结果为空的模板参数被省略，
+// 可用 {{if .参数名}}--flag={{.参数名}}{{end}} 表示可选参数
+type Config struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Parameters  json.RawMessage   `json:"parameters,omitempty"` // 参数JSON Schema，默认为任意对象
+	Command     string            `json:"command"`
+	Args        []string          `json:"args,omitempty"`
+	Dir         string            `json:"dir,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	Timeout     string            `json:"timeout,omitempty"` // 如 "30s"，为空时使用管理器的默认值
+
+	// Stdin 为true时将参数JSON写入命令的标准输入
+	Stdin bool `json:"stdin,omitempty"`
+
+	// Output 标准输出的解析方式：auto、json或text
+	Output string `json:"output,omitempty"`
+}
+
+// Tool 运行本地可执行文件的工具，实现tools.ContextTool和tools.TimeoutTool
+type Tool struct {
+	config     Config
+	schema     string
+	properties []string
+	args       []argTemplate
+	dir        *template.Template
+	env        map[string]*template.Template
+	timeout    time.Duration
+}
+
+// argTemplate 命令行参数模板，literal表示参数不含模板动作
+type argTemplate struct {
+	tmpl    *template.Template
+	literal bool
+}
+
+// templateFuncs 参数模板可用的函数
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// New 校验配置并编译其中的模板
+func New(config Config) (*Tool, error) {
+	if config.Name == "" {
+		return nil, errors.New("tool name is required")
+	}
+	if config.Command == "" {
+		return nil, fmt.Errorf("tool %s: command is required", config.Name)
+	}
+
+	switch config.Output {
+	case "":
+		config.Output = OutputAuto
+	case OutputAuto, OutputJSON, OutputText:
+	default:
+		return nil, fmt.Errorf("tool %s: unknown output %q", config.Name, config.Output)
+	}
+
+	t := &Tool{
+		config: config,
+		schema: `{"type":"object"}`,
+		env:    make(map[string]*template.Template, len(config.Env)),
+	}
+
+	if len(config.Parameters) > 0 {
+		paramSchema, err := schema.Parse(string(config.Parameters))
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: parameters: %w", config.Name, err)
+		}
+		t.schema = string(config.Parameters)
+		for name := range paramSchema.Properties {
+			t.properties = append(t.properties, name)
+		}
+	}
+
+	if config.Timeout != "" {
+		timeout, err := time.ParseDuration(config.Timeout)
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("tool %s: invalid timeout %q", config.Name, config.Timeout)
+		}
+		t.timeout = timeout
+	}
+
+	var err error
+	for i, arg := range config.Args {
+		tmpl, err := parseTemplate(fmt.Sprintf("%s.args[%d]", config.Name, i), arg)
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: %w", config.Name, err)
+		}
+		t.args = append(t.args, argTemplate{tmpl: tmpl, literal: !strings.Contains(arg, "{{")})
+	}
+	if t.dir, err = parseTemplate(config.Name+".dir", config.Dir); err != nil {
+		return nil, fmt.Errorf("tool %s: %w", config.Name, err)
+	}
+	for key, value := range config.Env {
+		if t.env[key], err = parseTemplate(config.Name+".env."+key, value); err != nil {
+			return nil, fmt.Errorf("tool %s: %w", config.Name, err)
+		}
+	}
+
+	return t, nil
+}
+
+// parseTemplate 编译参数模板，引用schema中未声明且请求中未提供的参数时渲染失败，
+// 而不是输出 <no value>
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// Name 返回工具名称
+func (t *Tool) Name() string {
+	return t.config.Name
+}
+
+// Description 返回工具描述
+func (t *Tool) Description() string {
+	return t.config.Description
+}
+
+// ParameterSchema 返回配置中声明的参数JSON Schema
+func (t *Tool) ParameterSchema() string {
+	return t.schema
+}
+
+// Timeout 实现tools.TimeoutTool接口
+func (t *Tool) Timeout() time.Duration {
+	return t.timeout
+}
+
+// ExecuteContext 运行命令并解析其标准输出，ctx取消或超时时终止命令的进程组
+func (t *Tool) ExecuteContext(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	// schema中声明但请求中未提供的参数在模板中为空字符串
+	data := make(map[string]interface{}, len(t.properties))
+	for _, name := range t.properties {
+		data[name] = ""
+	}
+	// 数字保留原始写法，避免1000000渲染为1e+06
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, tools.Errorf(tools.CodeInvalidParams, "Invalid parameters for tool %s: %v", t.config.Name, err)
+	}
+
+	args := make([]string, 0, len(t.args))
+	for _, a := range t.args {
+		arg, err := render(a.tmpl, data)
+		if err != nil {
+			return nil, tools.Errorf(tools.CodeInvalidParams, "Failed to render arguments: %v", err)
+		}
+		if arg == "" && !a.literal {
+			continue
+		}
+		args = append(args, arg)
+	}
+
+	cmd := exec.Command(t.config.Command, args...)
+	setProcessGroup(cmd)
+
+	dir, err := render(t.dir, data)
+	if err != nil {
+		return nil, tools.Errorf(tools.CodeInvalidParams, "Failed to render dir: %v", err)
+	}
+	cmd.Dir = dir
+
+	cmd.Env = os.Environ()
+	for key, tmpl := range t.env {
+		value, err := render(tmpl, data)
+		if err != nil {
+			return nil, tools.Errorf(tools.CodeInvalidParams, "Failed to render env %s: %v", key, err)
+		}
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	if t.config.Stdin {
+		cmd.Stdin = bytes.NewReader(params)
+	}
+
+	stdout := &limitedBuffer{limit: maxOutput}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+
+	if err := run(ctx, cmd); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, runError(err, stderr.Bytes())
+	}
+	if stdout.truncated {
+		return nil, tools.NewError(tools.CodeExecutionFailed,
+			fmt.Sprintf("Command output exceeds %d bytes", maxOutput),
+			map[string]interface{}{"max_output": maxOutput})
+	}
+
+	return t.parseOutput(stdout.Bytes())
+}
+
+// run 启动命令并等待其结束。ctx取消或超时时终止整个进程组，
+// 如sh -c启动的子进程也随之终止，不会继续占用输出管道
+func run(ctx context.Context, cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcessGroup(cmd)
+		case <-done:
+		}
+	}()
+
+	return cmd.Wait()
+}
+
+// render 使用参数执行模板
+func render(tmpl *template.Template, data map[string]interface{}) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// runError 将命令执行失败转换为工具错误，详情中包含退出码和标准错误输出
+func runError(err error, stderr []byte) error {
+	if len(stderr) > maxStderr {
+		stderr = stderr[len(stderr)-maxStderr:]
+	}
+	message := strings.TrimSpace(string(stderr))
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if message == "" {
+			message = exitErr.Error()
+		}
+		return tools.NewError(tools.CodeExecutionFailed,
+			fmt.Sprintf("Command exited with status %d: %s", exitErr.ExitCode(), message),
+			map[string]interface{}{
+				"exit_code": exitErr.ExitCode(),
+				"stderr":    message,
+			})
+	}
+
+	return tools.Errorf(tools.CodeExecutionFailed, "Failed to run command: %v", err)
+}
+
+// parseOutput 按配置解析标准输出
+func (t *Tool) parseOutput(stdout []byte) (interface{}, error) {
+	if t.config.Output == OutputText {
+		return string(stdout), nil
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(stdout, &result); err != nil {
+		if t.config.Output == OutputJSON {
+			return nil, tools.Errorf(tools.CodeExecutionFailed, "Command output is not valid JSON: %v", err)
+		}
+		return strings.TrimRight(string(stdout), "\n"), nil
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// File 外部命令工具配置文件的格式
+type File struct {
+	Tools []Config `json:"tools"`
+}
+
+// Loader 从配置文件注册外部命令工具，重新加载时替换已变化的工具并注销已删除的工具
+type Loader struct {
+	toolMgr *tools.ToolManager
+	path    string
+	names   map[string]bool // 上次加载时注册的工具名
+	mutex   sync.Mutex
+}
+
+// NewLoader 创建配置文件加载器
+func NewLoader(toolMgr *tools.ToolManager, path string) *Loader {
+	return &Loader{
+		toolMgr: toolMgr,
+		path:    path,
+		names:   make(map[string]bool),
+	}
+}
+
+// LoadFile 读取并校验配置文件中的所有工具
+func LoadFile(path string) ([]*Tool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	loaded := make([]*Tool, 0, len(file.Tools))
+	names := make(map[string]bool, len(file.Tools))
+	for _, config := range file.Tools {
+		tool, err := New(config)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if names[tool.Name()] {
+			return nil, fmt.Errorf("%s: duplicate tool %s", path, tool.Name())
+		}
+		names[tool.Name()] = true
+		loaded = append(loaded, tool)
+	}
+
+	return loaded, nil
+}
+
+// Load 加载配置文件并注册其中的工具。配置文件有任何错误时保留当前已注册的工具
+func (l *Loader) Load() error {
+	loaded, err := LoadFile(l.path)
+	if err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// 不能替换不是由本加载器注册的工具，否则重新加载删除该条目时会注销内置工具
+	for _, tool := range loaded {
+		if !l.names[tool.Name()] && l.toolMgr.HasTool(tool.Name()) {
+			return fmt.Errorf("%s: tool %s conflicts with an existing tool", l.path, tool.Name())
+		}
+	}
+
+	// 批量注册和注销，一次加载只发送一次工具列表变更通知
+	names := make(map[string]bool, len(loaded))
+	registered := make([]tools.ContextTool, 0, len(loaded))
+	for _, tool := range loaded {
+		registered = append(registered, tool)
+		names[tool.Name()] = true
+	}
+
+	var stale []string
+	for name := range l.names {
+		if !names[name] {
+			stale = append(stale, name)
+		}
+	}
+	l.toolMgr.UpdateTools(registered, stale)
+	l.names = names
+
+	log.Printf("Loaded %d command tool(s) from %s", len(loaded), l.path)
+	return nil
+}
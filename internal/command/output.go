@@ -0,0 +1,29 @@
+package command
+
+import "bytes"
+
+// maxOutput 保留的标准输出的最大字节数，超出时调用失败
+const maxOutput = 1 << 20
+
+// limitedBuffer 最多保留limit字节的缓冲区，超出部分被丢弃并记录truncated。
+// Write总是报告全部写入，命令不会因输出管道被关闭而提前退出
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write 实现io.Writer接口
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); len(p) > remaining {
+		b.truncated = true
+		b.buf.Write(p[:remaining])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// Bytes 返回保留的输出
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
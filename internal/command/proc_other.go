@@ -0,0 +1,15 @@
+//go:build !unix
+
+package command
+
+import "os/exec"
+
+// setProcessGroup 不支持进程组的平台上不做任何处理
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup 不支持进程组的平台上只终止命令本身
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,84 @@
+package document
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/droid/go-mcp/internal/resources"
+)
+
+// URIScheme 文档资源的URI前缀
+const URIScheme = "doc://"
+
+// MimeType 返回文档类型对应的MIME类型
+func (dt DocumentType) MimeType() string {
+	switch dt {
+	case TypeHTML:
+		return "text/html"
+	case TypeJSON:
+		return "application/json"
+	case TypeMarkdown:
+		return "text/markdown"
+	default:
+		return "text/plain"
+	}
+}
+
+// DocumentURI 返回文档的资源URI
+func DocumentURI(id string) string {
+	return URIScheme + id
+}
+
+// ListResources 实现resources.Provider接口
+func (t *DocumentTool) ListResources(ctx context.Context) ([]resources.Resource, error) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	items := make([]resources.Resource, 0, len(t.documents))
+	for _, doc := range t.documents {
+		items = append(items, resources.Resource{
+			URI:      DocumentURI(doc.ID),
+			Name:     doc.Title,
+			MimeType: doc.Type.MimeType(),
+		})
+	}
+
+	// 按URI排序，保证列表顺序稳定
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].URI < items[j].URI
+	})
+
+	return items, nil
+}
+
+// ListResourceTemplates 实现resources.Provider接口
+func (t *DocumentTool) ListResourceTemplates(ctx context.Context) ([]resources.ResourceTemplate, error) {
+	return []resources.ResourceTemplate{
+		{
+			URITemplate: URIScheme + "{id}",
+			Name:        "document",
+			Description: "按ID读取文档内容",
+		},
+	}, nil
+}
+
+// ReadResource 实现resources.Provider接口
+func (t *DocumentTool) ReadResource(ctx context.Context, uri string) ([]resources.Contents, error) {
+	if !strings.HasPrefix(uri, URIScheme) {
+		return nil, resources.ErrNotFound
+	}
+
+	doc, exists := t.getDocument(strings.TrimPrefix(uri, URIScheme))
+	if !exists {
+		return nil, resources.ErrNotFound
+	}
+
+	return []resources.Contents{
+		{
+			URI:      uri,
+			MimeType: doc.Type.MimeType(),
+			Text:     doc.Content,
+		},
+	}, nil
+}
@@ -1,11 +1,16 @@
 package document
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
+
+	"github.com/droid/go-mcp/internal/resources"
+	"github.com/droid/go-mcp/internal/schema"
+	"github.com/droid/go-mcp/internal/tools"
 )
 
 // DocumentType 定义文档类型
@@ -30,30 +35,50 @@ type Document struct {
 
 // SummarizeParams 摘要参数
 type SummarizeParams struct {
-	DocumentID string `json:"document_id"`
-	Content    string `json:"content,omitempty"`
-	MaxLength  int    `json:"max_length,omitempty"`
-	Format     string `json:"format,omitempty"`
+	DocumentID string `json:"document_id,omitempty" description:"要摘要的文档ID"`
+	Content    string `json:"content,omitempty" description:"要摘要的内容（如果没有提供document_id）"`
+	MaxLength  int    `json:"max_length,omitempty" description:"摘要的最大长度"`
+	Format     string `json:"format,omitempty" description:"摘要的格式" enum:"text,bullet_points,json"`
 }
 
 // ConvertParams 转换参数
 type ConvertParams struct {
-	Content  string       `json:"content"`
-	FromType DocumentType `json:"from_type"`
-	ToType   DocumentType `json:"to_type"`
+	Content  string       `json:"content" description:"要转换的内容"`
+	FromType DocumentType `json:"from_type" description:"源格式" enum:"text,html,json,markdown"`
+	ToType   DocumentType `json:"to_type" description:"目标格式" enum:"text,html,json,markdown"`
 }
 
 // ExtractParams 提取参数
 type ExtractParams struct {
-	Content string   `json:"content"`
-	Type    string   `json:"type"`
-	Fields  []string `json:"fields,omitempty"`
+	Content string   `json:"content" description:"要提取的内容"`
+	Type    string   `json:"type" description:"提取类型" enum:"entities,keywords,structured_data"`
+	Fields  []string `json:"fields,omitempty" description:"要提取的字段（用于structured_data类型）"`
 }
 
+// SaveParams 保存参数，ID为空时创建新文档
+type SaveParams struct {
+	ID      string       `json:"id,omitempty" description:"要更新的文档ID（为空时创建新文档）"`
+	Title   string       `json:"title" description:"文档标题"`
+	Content string       `json:"content" description:"文档内容"`
+	Type    DocumentType `json:"type,omitempty" description:"文档类型" enum:"text,html,json,markdown"`
+}
+
+// documentSchema 按action区分操作的参数schema，由各操作的参数结构体生成
+var documentSchema = schema.MustDiscriminated("action",
+	schema.Variant{Value: "summarize", Description: "摘要操作", Params: SummarizeParams{}},
+	schema.Variant{Value: "convert", Description: "转换操作", Params: ConvertParams{}},
+	schema.Variant{Value: "extract", Description: "提取操作", Params: ExtractParams{}},
+	schema.Variant{Value: "save", Description: "保存操作", Params: SaveParams{}},
+).String()
+
 // DocumentTool 实现文档处理功能
 type DocumentTool struct {
 	documents map[string]Document
 	nextID    int
+	mutex     sync.RWMutex
+
+	// 文档变更时通知资源订阅者
+	changeHandlers []func(resources.Change)
 }
 
 // NewDocumentTool 创建新的文档工具
@@ -87,132 +112,61 @@ func (t *DocumentTool) Name() string {
 
 // Description 实现Tool接口
 func (t *DocumentTool) Description() string {
-	return "处理、转换和提取文档内容"
+	return "处理、转换、提取和保存文档内容"
 }
 
 // ParameterSchema 实现Tool接口
 func (t *DocumentTool) ParameterSchema() string {
-	return `{
-		"oneOf": [
-			{
-				"type": "object",
-				"properties": {
-					"action": {
-						"type": "string",
-						"enum": ["summarize"],
-						"description": "摘要操作"
-					},
-					"document_id": {
-						"type": "string",
-						"description": "要摘要的文档ID"
-					},
-					"content": {
-						"type": "string",
-						"description": "要摘要的内容（如果没有提供document_id）"
-					},
-					"max_length": {
-						"type": "integer",
-						"description": "摘要的最大长度"
-					},
-					"format": {
-						"type": "string",
-						"enum": ["text", "bullet_points", "json"],
-						"description": "摘要的格式"
-					}
-				},
-				"required": ["action"]
-			},
-			{
-				"type": "object",
-				"properties": {
-					"action": {
-						"type": "string",
-						"enum": ["convert"],
-						"description": "转换操作"
-					},
-					"content": {
-						"type": "string",
-						"description": "要转换的内容"
-					},
-					"from_type": {
-						"type": "string",
-						"enum": ["text", "html", "json", "markdown"],
-						"description": "源格式"
-					},
-					"to_type": {
-						"type": "string",
-						"enum": ["text", "html", "json", "markdown"],
-						"description": "目标格式"
-					}
-				},
-				"required": ["action", "content", "from_type", "to_type"]
-			},
-			{
-				"type": "object",
-				"properties": {
-					"action": {
-						"type": "string",
-						"enum": ["extract"],
-						"description": "提取操作"
-					},
-					"content": {
-						"type": "string",
-						"description": "要提取的内容"
-					},
-					"type": {
-						"type": "string",
-						"enum": ["entities", "keywords", "structured_data"],
-						"description": "提取类型"
-					},
-					"fields": {
-						"type": "array",
-						"items": {
-							"type": "string"
-						},
-						"description": "要提取的字段（用于structured_data类型）"
-					}
-				},
-				"required": ["action", "content", "type"]
-			}
-		]
-	}`
+	return documentSchema
 }
 
 // Execute 实现Tool接口
 func (t *DocumentTool) Execute(paramsJSON json.RawMessage) (interface{}, error) {
+	return t.ExecuteContext(context.Background(), paramsJSON)
+}
+
+// ExecuteContext 实现ContextTool接口
+func (t *DocumentTool) ExecuteContext(ctx context.Context, paramsJSON json.RawMessage) (interface{}, error) {
 	// 首先解析动作类型
 	var baseParams struct {
 		Action string `json:"action"`
 	}
 
 	if err := json.Unmarshal(paramsJSON, &baseParams); err != nil {
-		return nil, errors.New("无效的参数: " + err.Error())
+		return nil, tools.Errorf(tools.CodeInvalidParams, "无效的参数: %v", err)
 	}
 
 	switch baseParams.Action {
 	case "summarize":
 		var params SummarizeParams
 		if err := json.Unmarshal(paramsJSON, &params); err != nil {
-			return nil, errors.New("无效的摘要参数: " + err.Error())
+			return nil, tools.Errorf(tools.CodeInvalidParams, "无效的摘要参数: %v", err)
 		}
 		return t.summarize(params)
 
 	case "convert":
 		var params ConvertParams
 		if err := json.Unmarshal(paramsJSON, &params); err != nil {
-			return nil, errors.New("无效的转换参数: " + err.Error())
+			return nil, tools.Errorf(tools.CodeInvalidParams, "无效的转换参数: %v", err)
 		}
-		return t.convert(params)
+		return t.convert(ctx, params)
 
 	case "extract":
 		var params ExtractParams
 		if err := json.Unmarshal(paramsJSON, &params); err != nil {
-			return nil, errors.New("无效的提取参数: " + err.Error())
+			return nil, tools.Errorf(tools.CodeInvalidParams, "无效的提取参数: %v", err)
 		}
 		return t.extract(params)
 
+	case "save":
+		var params SaveParams
+		if err := json.Unmarshal(paramsJSON, &params); err != nil {
+			return nil, tools.Errorf(tools.CodeInvalidParams, "无效的保存参数: %v", err)
+		}
+		return t.save(params)
+
 	default:
-		return nil, fmt.Errorf("不支持的操作: %s", baseParams.Action)
+		return nil, tools.Errorf(tools.CodeInvalidParams, "不支持的操作: %s", baseParams.Action)
 	}
 }
 
@@ -221,15 +175,16 @@ func (t *DocumentTool) summarize(params SummarizeParams) (interface{}, error) {
 	// 获取内容
 	content := params.Content
 	if content == "" && params.DocumentID != "" {
-		doc, exists := t.documents[params.DocumentID]
+		doc, exists := t.getDocument(params.DocumentID)
 		if !exists {
-			return nil, fmt.Errorf("文档不存在: %s", params.DocumentID)
+			return nil, tools.NewError(tools.CodeExecutionFailed, "文档不存在: "+params.DocumentID,
+				map[string]interface{}{"document_id": params.DocumentID})
 		}
 		content = doc.Content
 	}
 
 	if content == "" {
-		return nil, errors.New("没有提供内容")
+		return nil, tools.NewError(tools.CodeInvalidParams, "没有提供内容", nil)
 	}
 
 	// 根据内容类型提取纯文本
@@ -278,19 +233,19 @@ func (t *DocumentTool) summarize(params SummarizeParams) (interface{}, error) {
 		return map[string]string{"summary": summary}, nil
 
 	default:
-		return nil, fmt.Errorf("不支持的格式: %s", format)
+		return nil, tools.Errorf(tools.CodeInvalidParams, "不支持的格式: %s", format)
 	}
 }
 
-// convert 模拟文档格式转换
-func (t *DocumentTool) convert(params ConvertParams) (interface{}, error) {
+// convert 模拟文档格式转换，按段落报告进度
+func (t *DocumentTool) convert(ctx context.Context, params ConvertParams) (interface{}, error) {
 	if params.Content == "" {
-		return nil, errors.New("内容不能为空")
+		return nil, tools.NewError(tools.CodeInvalidParams, "内容不能为空", nil)
 	}
 
 	// 验证支持的类型
 	if !isValidDocType(params.FromType) || !isValidDocType(params.ToType) {
-		return nil, errors.New("不支持的文档类型")
+		return nil, tools.NewError(tools.CodeInvalidParams, "不支持的文档类型", nil)
 	}
 
 	// 如果源类型和目标类型相同，直接返回
@@ -311,10 +266,17 @@ func (t *DocumentTool) convert(params ConvertParams) (interface{}, error) {
 		content = strings.ReplaceAll(content, "## ", "<h2>") + "</h2>"
 		// 处理段落
 		paragraphs := strings.Split(content, "\n\n")
+		progress := tools.ProgressFromContext(ctx)
+		total := float64(len(paragraphs))
 		for i, p := range paragraphs {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
 			if !strings.HasPrefix(p, "<h") {
 				paragraphs[i] = "<p>" + p + "</p>"
 			}
+			progress.Report(float64(i+1), total, fmt.Sprintf("已转换 %d/%d 段", i+1, len(paragraphs)))
 		}
 		convertedContent = "<html><body>" + strings.Join(paragraphs, "") + "</body></html>"
 
@@ -354,7 +316,7 @@ func (t *DocumentTool) convert(params ConvertParams) (interface{}, error) {
 // extract 模拟从文档中提取信息
 func (t *DocumentTool) extract(params ExtractParams) (interface{}, error) {
 	if params.Content == "" {
-		return nil, errors.New("内容不能为空")
+		return nil, tools.NewError(tools.CodeInvalidParams, "内容不能为空", nil)
 	}
 
 	// 根据提取类型进行不同处理
@@ -465,10 +427,87 @@ func (t *DocumentTool) extract(params ExtractParams) (interface{}, error) {
 		return result, nil
 
 	default:
-		return nil, fmt.Errorf("不支持的提取类型: %s", params.Type)
+		return nil, tools.Errorf(tools.CodeInvalidParams, "不支持的提取类型: %s", params.Type)
 	}
 }
 
+// save 创建或更新文档
+func (t *DocumentTool) save(params SaveParams) (interface{}, error) {
+	if params.Title == "" || params.Content == "" {
+		return nil, tools.NewError(tools.CodeInvalidParams, "标题和内容不能为空", nil)
+	}
+
+	docType := params.Type
+	if docType == "" {
+		docType = TypeText
+	}
+	if !isValidDocType(docType) {
+		return nil, tools.NewError(tools.CodeInvalidParams, "不支持的文档类型", nil)
+	}
+
+	doc, created := t.SaveDocument(Document{
+		ID:      params.ID,
+		Title:   params.Title,
+		Content: params.Content,
+		Type:    docType,
+	})
+
+	// 附带新文档的资源链接，客户端可通过resources/read读取
+	return tools.StructuredResult(map[string]interface{}{
+		"id":      doc.ID,
+		"uri":     DocumentURI(doc.ID),
+		"created": created,
+	}, tools.ResourceLink(resources.Resource{
+		URI:      DocumentURI(doc.ID),
+		Name:     doc.Title,
+		MimeType: doc.Type.MimeType(),
+	}))
+}
+
+// SaveDocument 保存文档并通知资源变更，ID为空时分配新ID；返回保存后的文档及是否为新建
+func (t *DocumentTool) SaveDocument(doc Document) (Document, bool) {
+	t.mutex.Lock()
+	for doc.ID == "" {
+		id := fmt.Sprintf("doc-%d", t.nextID)
+		t.nextID++
+		if _, taken := t.documents[id]; !taken {
+			doc.ID = id
+		}
+	}
+	_, exists := t.documents[doc.ID]
+	t.documents[doc.ID] = doc
+	handlers := make([]func(resources.Change), len(t.changeHandlers))
+	copy(handlers, t.changeHandlers)
+	t.mutex.Unlock()
+
+	change := resources.Change{Kind: resources.ChangeUpdated, URI: DocumentURI(doc.ID)}
+	if !exists {
+		change = resources.Change{Kind: resources.ChangeListChanged}
+	}
+	for _, handler := range handlers {
+		handler(change)
+	}
+
+	return doc, !exists
+}
+
+// OnChange 实现resources.ChangeNotifier接口
+func (t *DocumentTool) OnChange(handler func(resources.Change)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.changeHandlers = append(t.changeHandlers, handler)
+}
+
+// getDocument 按ID获取文档
+func (t *DocumentTool) getDocument(id string) (Document, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	doc, exists := t.documents[id]
+	return doc, exists
+}
+
 // 辅助函数：验证文档类型是否合法
 func isValidDocType(docType DocumentType) bool {
 	validTypes := []DocumentType{TypeText, TypeHTML, TypeJSON, TypeMarkdown}
@@ -0,0 +1,350 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// 网关作为客户端时使用的协议版本和客户端信息
+const (
+	protocolVersion = "2025-06-18"
+	clientName      = "go-mcp-gateway"
+	clientVersion   = "1.0.0"
+)
+
+// errDisconnected 下游连接已断开
+var errDisconnected = errors.New("downstream server disconnected")
+
+// message 下游发来的消息：响应、通知或请求
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *jsonrpc.Error  `json:"error,omitempty"`
+}
+
+// request 发往下游的请求
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int64       `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// remoteTool 下游tools/list返回的工具
+type remoteTool struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description,omitempty"`
+	InputSchema  json.RawMessage `json:"inputSchema,omitempty"`
+	OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+}
+
+// callResult 下游tools/call的结果
+type callResult struct {
+	Content           []tools.Content        `json:"content"`
+	StructuredContent interface{}            `json:"structuredContent,omitempty"`
+	IsError           bool                   `json:"isError,omitempty"`
+	Meta              map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// progressQueueSize 每个下游等待转发的进度通知数，队列已满时新的进度被丢弃
+const progressQueueSize = 64
+
+// progressUpdate 等待转发给调用方的一条下游进度
+type progressUpdate struct {
+	reporter tools.ProgressReporter
+	progress float64
+	total    float64
+	message  string
+}
+
+// client 连接一个下游MCP服务器的JSON-RPC客户端
+type client struct {
+	name      string
+	transport transport
+
+	mutex    sync.Mutex
+	nextID   int64
+	pending  map[int64]chan message
+	progress map[string]tools.ProgressReporter
+
+	// progressQueue 由forwardProgress转发的进度，readLoop不因调用方发送进度而阻塞
+	progressQueue chan progressUpdate
+
+	// onToolsChanged 下游工具列表变化时调用
+	onToolsChanged func()
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newClient 创建客户端并开始读取下游消息
+func newClient(name string, t transport, onToolsChanged func()) *client {
+	c := &client{
+		name:           name,
+		transport:      t,
+		pending:        make(map[int64]chan message),
+		progress:       make(map[string]tools.ProgressReporter),
+		progressQueue:  make(chan progressUpdate, progressQueueSize),
+		onToolsChanged: onToolsChanged,
+		done:           make(chan struct{}),
+	}
+
+	go c.readLoop()
+	go c.forwardProgress()
+	return c
+}
+
+// readLoop 读取下游消息直到连接断开
+func (c *client) readLoop() {
+	defer c.close()
+
+	for {
+		data, err := c.transport.receive()
+		if err != nil {
+			return
+		}
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("Invalid message from downstream %s: %v", c.name, err)
+			continue
+		}
+
+		switch {
+		case msg.Method != "" && len(msg.ID) > 0:
+			c.handleRequest(msg)
+		case msg.Method != "":
+			c.handleNotification(msg)
+		default:
+			c.handleResponse(msg)
+		}
+	}
+}
+
+// handleResponse 将响应交给等待中的调用
+func (c *client) handleResponse(msg message) {
+	id, err := strconv.ParseInt(string(msg.ID), 10, 64)
+	if err != nil {
+		log.Printf("Unexpected response id from downstream %s: %s", c.name, msg.ID)
+		return
+	}
+
+	c.mutex.Lock()
+	ch, exists := c.pending[id]
+	delete(c.pending, id)
+	c.mutex.Unlock()
+
+	if exists {
+		ch <- msg
+	}
+}
+
+// handleNotification 处理下游通知：转发进度，工具列表变化时重新同步工具
+func (c *client) handleNotification(msg message) {
+	switch msg.Method {
+	case "notifications/progress":
+		var params struct {
+			ProgressToken json.RawMessage `json:"progressToken"`
+			Progress      float64         `json:"progress"`
+			Total         float64         `json:"total,omitempty"`
+			Message       string          `json:"message,omitempty"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return
+		}
+
+		c.mutex.Lock()
+		reporter, exists := c.progress[string(params.ProgressToken)]
+		c.mutex.Unlock()
+
+		if !exists {
+			return
+		}
+		select {
+		case c.progressQueue <- progressUpdate{
+			reporter: reporter,
+			progress: params.Progress,
+			total:    params.Total,
+			message:  params.Message,
+		}:
+		default:
+			// 调用方处理进度过慢，丢弃这条进度，后续进度仍会送达
+		}
+
+	case "notifications/tools/list_changed":
+		if c.onToolsChanged != nil {
+			go c.onToolsChanged()
+		}
+	}
+}
+
+// forwardProgress 将下游进度转发给调用方，直到连接关闭
+func (c *client) forwardProgress() {
+	for {
+		select {
+		case update := <-c.progressQueue:
+			update.reporter.Report(update.progress, update.total, update.message)
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// handleRequest 响应下游发起的请求，网关只支持ping
+func (c *client) handleRequest(msg message) {
+	var response jsonrpc.Response
+	if msg.Method == "ping" {
+		response = jsonrpc.NewResponse(msg.ID, struct{}{})
+	} else {
+		response = jsonrpc.NewErrorResponse(msg.ID,
+			jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "Method not found", msg.Method))
+	}
+	c.write(response)
+}
+
+// write 序列化并发送消息
+func (c *client) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.transport.send(data)
+}
+
+// call 发送请求并等待响应。ctx取消时向下游发送notifications/cancelled；
+// reporter不为nil时请求携带progressToken，下游的进度转发给reporter
+func (c *client) call(ctx context.Context, method string, params map[string]interface{}, reporter tools.ProgressReporter, result interface{}) error {
+	ch := make(chan message, 1)
+
+	c.mutex.Lock()
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	token := strconv.Quote(fmt.Sprintf("%s-%d", c.name, id))
+	if reporter != nil {
+		c.progress[token] = reporter
+		if params == nil {
+			params = make(map[string]interface{})
+		}
+		params["_meta"] = map[string]interface{}{"progressToken": json.RawMessage(token)}
+	}
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		delete(c.pending, id)
+		delete(c.progress, token)
+		c.mutex.Unlock()
+	}()
+
+	if err := c.write(request{JSONRPC: jsonrpc.Version, ID: id, Method: method, Params: params}); err != nil {
+		return errDisconnected
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+
+	case <-ctx.Done():
+		c.write(jsonrpc.NewNotification("notifications/cancelled", map[string]interface{}{
+			"requestId": id,
+			"reason":    ctx.Err().Error(),
+		}))
+		return ctx.Err()
+
+	case <-c.done:
+		return errDisconnected
+	}
+}
+
+// initialize 完成与下游的握手
+func (c *client) initialize(ctx context.Context) error {
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+
+	err := c.call(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]interface{}{
+			"name":    clientName,
+			"version": clientVersion,
+		},
+	}, nil, &result)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Connected to downstream %s: %s %s (protocol %s)",
+		c.name, result.ServerInfo.Name, result.ServerInfo.Version, result.ProtocolVersion)
+
+	return c.write(jsonrpc.NewNotification("notifications/initialized", nil))
+}
+
+// listTools 获取下游的全部工具，处理分页
+func (c *client) listTools(ctx context.Context) ([]remoteTool, error) {
+	var all []remoteTool
+	cursor := ""
+
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+
+		var result struct {
+			Tools      []remoteTool `json:"tools"`
+			NextCursor string       `json:"nextCursor,omitempty"`
+		}
+		if err := c.call(ctx, "tools/list", params, nil, &result); err != nil {
+			return nil, err
+		}
+
+		all = append(all, result.Tools...)
+		if result.NextCursor == "" {
+			return all, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// callTool 调用下游工具
+func (c *client) callTool(ctx context.Context, name string, arguments json.RawMessage, reporter tools.ProgressReporter) (*callResult, error) {
+	var result callResult
+	err := c.call(ctx, "tools/call", map[string]interface{}{
+		"name":      name,
+		"arguments": arguments,
+	}, reporter, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// close 关闭连接，等待中的调用返回errDisconnected
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.transport.close()
+	})
+}
@@ -0,0 +1,267 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// 重连下游服务器的等待时间，每次失败翻倍直到上限
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// requestTimeout 握手和获取工具列表的超时时间
+const requestTimeout = 30 * time.Second
+
+// Config 一个下游MCP服务器，Command和URL二选一：
+// Command以子进程方式通过stdio连接，URL通过WebSocket连接
+type Config struct {
+	Name string `json:"name"`
+	// Prefix 导入工具名的前缀，默认为Name加下划线
+	Prefix string `json:"prefix,omitempty"`
+
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Dir     string            `json:"dir,omitempty"`
+
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// File 下游服务器配置文件的格式
+type File struct {
+	Servers []Config `json:"servers"`
+}
+
+// Gateway 连接下游MCP服务器并将其工具以带前缀的名称注册到工具管理器，
+// 下游断开时移除其工具并自动重连
+type Gateway struct {
+	toolMgr   *tools.ToolManager
+	downs     map[string]*downstream
+	mutex     sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	waitGroup sync.WaitGroup
+}
+
+// New 创建网关
+func New(toolMgr *tools.ToolManager) *Gateway {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Gateway{
+		toolMgr: toolMgr,
+		downs:   make(map[string]*downstream),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// LoadFile 读取配置文件并添加其中的所有下游服务器
+func (g *Gateway) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, config := range file.Servers {
+		if err := g.Add(config); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Add 添加下游服务器并在后台连接，连接失败时按退避时间重试
+func (g *Gateway) Add(config Config) error {
+	if config.Name == "" {
+		return fmt.Errorf("downstream server name is required")
+	}
+	if (config.Command == "") == (config.URL == "") {
+		return fmt.Errorf("downstream server %s: exactly one of command and url is required", config.Name)
+	}
+	if config.Prefix == "" {
+		config.Prefix = config.Name + "_"
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if _, exists := g.downs[config.Name]; exists {
+		return fmt.Errorf("duplicate downstream server %s", config.Name)
+	}
+
+	d := &downstream{
+		config:  config,
+		toolMgr: g.toolMgr,
+		tools:   make(map[string]bool),
+	}
+	g.downs[config.Name] = d
+
+	g.waitGroup.Add(1)
+	go func() {
+		defer g.waitGroup.Done()
+		d.run(g.ctx)
+	}()
+	return nil
+}
+
+// Close 断开所有下游服务器并移除其工具
+func (g *Gateway) Close() {
+	g.cancel()
+	g.waitGroup.Wait()
+}
+
+// downstream 一个下游服务器的连接状态
+type downstream struct {
+	config  Config
+	toolMgr *tools.ToolManager
+
+	mutex  sync.Mutex
+	client *client
+	// tools 已注册的工具名（带前缀）
+	tools map[string]bool
+}
+
+// run 保持与下游的连接，每次断开后移除工具并重连，直到ctx被取消
+func (d *downstream) run(ctx context.Context) {
+	backoff := minBackoff
+
+	for {
+		c, err := d.connect(ctx)
+		if err != nil {
+			log.Printf("Failed to connect to downstream %s: %v, retrying in %v", d.config.Name, err, backoff)
+		} else {
+			backoff = minBackoff
+
+			select {
+			case <-c.done:
+				log.Printf("Downstream %s disconnected", d.config.Name)
+			case <-ctx.Done():
+				c.close()
+			}
+			d.removeTools()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// connect 建立连接、完成握手并导入工具
+func (d *downstream) connect(ctx context.Context) (*client, error) {
+	var t transport
+	var err error
+	if d.config.Command != "" {
+		t, err = startStdio(d.config.Name, d.config)
+	} else {
+		t, err = dialWebSocket(d.config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c *client
+	c = newClient(d.config.Name, t, func() {
+		if err := d.sync(ctx, c); err != nil {
+			log.Printf("Failed to refresh tools from downstream %s: %v", d.config.Name, err)
+		}
+	})
+
+	initCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	if err := c.initialize(initCtx); err != nil {
+		c.close()
+		return nil, err
+	}
+
+	d.mutex.Lock()
+	d.client = c
+	d.mutex.Unlock()
+
+	if err := d.sync(ctx, c); err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// sync 获取下游工具列表，注册新工具并移除下游已不再提供的工具。
+// 与本地工具同名的下游工具被跳过
+func (d *downstream) sync(ctx context.Context, c *client) error {
+	listCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	remote, err := c.listTools(listCtx)
+	if err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.client != c {
+		return nil
+	}
+
+	current := make(map[string]bool, len(remote))
+	proxies := make([]tools.ContextTool, 0, len(remote))
+	for _, rt := range remote {
+		name := d.config.Prefix + rt.Name
+		if !d.tools[name] && d.toolMgr.HasTool(name) {
+			log.Printf("Downstream %s: tool %s conflicts with an existing tool, skipped", d.config.Name, name)
+			continue
+		}
+
+		proxies = append(proxies, newProxyTool(name, d.config.Name, rt, c))
+		current[name] = true
+	}
+
+	var stale []string
+	for name := range d.tools {
+		if !current[name] {
+			stale = append(stale, name)
+		}
+	}
+
+	// 批量注册和注销，客户端每次同步只收到一次工具列表变化通知
+	d.toolMgr.UpdateTools(proxies, stale)
+	d.tools = current
+
+	log.Printf("Imported %d tool(s) from downstream %s", len(current), d.config.Name)
+	return nil
+}
+
+// removeTools 移除下游的全部工具，工具管理器据此通知客户端一次工具列表变化
+func (d *downstream) removeTools() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	names := make([]string, 0, len(d.tools))
+	for name := range d.tools {
+		names = append(names, name)
+	}
+	d.toolMgr.UnregisterTools(names...)
+	d.tools = make(map[string]bool)
+	d.client = nil
+}
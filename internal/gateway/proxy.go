@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// proxyTool 将调用转发给下游服务器的工具，实现tools.ContextTool
+type proxyTool struct {
+	name         string
+	server       string
+	remote       remoteTool
+	client       *client
+	schema       string
+	outputSchema string
+}
+
+// newProxyTool 创建代理工具，名称为带前缀的工具名
+func newProxyTool(name, server string, remote remoteTool, c *client) *proxyTool {
+	schema := `{"type":"object"}`
+	if len(remote.InputSchema) > 0 {
+		schema = string(remote.InputSchema)
+	}
+
+	return &proxyTool{
+		name:         name,
+		server:       server,
+		remote:       remote,
+		client:       c,
+		schema:       schema,
+		outputSchema: string(remote.OutputSchema),
+	}
+}
+
+// Name 返回工具名称
+func (t *proxyTool) Name() string {
+	return t.name
+}
+
+// Description 返回下游工具的描述
+func (t *proxyTool) Description() string {
+	return t.remote.Description
+}
+
+// ParameterSchema 返回下游工具的参数schema
+func (t *proxyTool) ParameterSchema() string {
+	return t.schema
+}
+
+// OutputSchema 实现OutputSchemaTool接口，返回下游工具声明的输出schema
+func (t *proxyTool) OutputSchema() string {
+	return t.outputSchema
+}
+
+// ExecuteContext 调用下游工具，进度通知转发给调用方，ctx取消时下游调用一并取消
+func (t *proxyTool) ExecuteContext(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+
+	result, err := t.client.callTool(ctx, t.remote.Name, params, tools.ProgressFromContext(ctx))
+	if err != nil {
+		return nil, t.callError(err)
+	}
+
+	if result.IsError {
+		return nil, resultError(result)
+	}
+
+	return &tools.Result{
+		Content:           result.Content,
+		StructuredContent: result.StructuredContent,
+	}, nil
+}
+
+// callError 将调用下游时的错误转换为工具错误，下游返回的错误码被保留
+func (t *proxyTool) callError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if errors.Is(err, errDisconnected) {
+		return tools.Errorf(tools.CodeExecutionFailed, "Downstream server %s disconnected", t.server)
+	}
+
+	var rpcErr *jsonrpc.Error
+	if !errors.As(err, &rpcErr) {
+		return tools.Errorf(tools.CodeExecutionFailed, "Invalid response from downstream server %s: %v", t.server, err)
+	}
+
+	// 下游是本服务器时，错误数据中携带工具错误码和详情
+	details, _ := rpcErr.Data.(map[string]interface{})
+	code, _ := details["code"].(string)
+	if code == "" {
+		switch rpcErr.Code {
+		case jsonrpc.CodeInvalidParams:
+			code = tools.CodeInvalidParams
+		case jsonrpc.CodePermissionDenied:
+			code = tools.CodePermissionDenied
+		default:
+			code = tools.CodeExecutionFailed
+		}
+		details = map[string]interface{}{"downstream_error": rpcErr.Code}
+		if rpcErr.Data != nil {
+			details["data"] = rpcErr.Data
+		}
+	} else {
+		delete(details, "code")
+	}
+
+	return tools.NewError(code, rpcErr.Message, details)
+}
+
+// resultError 将下游的isError结果转换为工具错误，_meta中的错误码和详情被保留
+func resultError(result *callResult) error {
+	var texts []string
+	for _, content := range result.Content {
+		if content.Type == tools.ContentText && content.Text != "" {
+			texts = append(texts, content.Text)
+		}
+	}
+	message := strings.Join(texts, "\n")
+	if message == "" {
+		message = "Downstream tool failed"
+	}
+
+	code, _ := result.Meta["code"].(string)
+	if code == "" {
+		code = tools.CodeExecutionFailed
+	}
+
+	var details map[string]interface{}
+	for key, value := range result.Meta {
+		if key == "code" {
+			continue
+		}
+		if details == nil {
+			details = make(map[string]interface{})
+		}
+		details[key] = value
+	}
+
+	return tools.NewError(code, message, details)
+}
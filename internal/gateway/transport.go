@@ -0,0 +1,163 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// transport 与下游服务器之间收发JSON-RPC消息
+type transport interface {
+	// send 发送一条消息
+	send(data []byte) error
+	// receive 阻塞读取下一条消息，连接断开时返回错误
+	receive() ([]byte, error)
+	// close 关闭连接，子进程被终止
+	close() error
+}
+
+// stdioTransport 通过子进程的标准输入输出收发换行分隔的消息
+type stdioTransport struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	mutex  sync.Mutex
+}
+
+// startStdio 启动子进程，子进程的标准错误写入日志
+func startStdio(name string, config Config) (*stdioTransport, error) {
+	cmd := exec.Command(config.Command, config.Args...)
+	cmd.Dir = config.Dir
+	cmd.Env = os.Environ()
+	for key, value := range config.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Stderr = &logWriter{prefix: fmt.Sprintf("[%s] ", name)}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &stdioTransport{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+	}, nil
+}
+
+// send 实现transport接口
+func (t *stdioTransport) send(data []byte) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	_, err := t.stdin.Write(append(data, '\n'))
+	return err
+}
+
+// receive 实现transport接口，跳过空行
+func (t *stdioTransport) receive() ([]byte, error) {
+	for {
+		line, err := t.stdout.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// close 实现transport接口，关闭标准输入并终止子进程
+func (t *stdioTransport) close() error {
+	t.stdin.Close()
+	if t.cmd.Process != nil {
+		t.cmd.Process.Kill()
+	}
+	return t.cmd.Wait()
+}
+
+// wsTransport 通过WebSocket连接收发消息
+type wsTransport struct {
+	conn  *websocket.Conn
+	mutex sync.Mutex
+}
+
+// dialWebSocket 以mcp子协议连接下游服务器
+func dialWebSocket(config Config) (*wsTransport, error) {
+	dialer := websocket.Dialer{
+		Subprotocols:     []string{"mcp"},
+		HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+		Proxy:            http.ProxyFromEnvironment,
+	}
+
+	header := http.Header{}
+	for key, value := range config.Headers {
+		header.Set(key, value)
+	}
+
+	conn, _, err := dialer.Dial(config.URL, header)
+	if err != nil {
+		return nil, err
+	}
+	return &wsTransport{conn: conn}, nil
+}
+
+// send 实现transport接口
+func (t *wsTransport) send(data []byte) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// receive 实现transport接口
+func (t *wsTransport) receive() ([]byte, error) {
+	_, data, err := t.conn.ReadMessage()
+	return data, err
+}
+
+// close 实现transport接口
+func (t *wsTransport) close() error {
+	return t.conn.Close()
+}
+
+// logWriter 将子进程的输出按行写入日志
+type logWriter struct {
+	prefix string
+	buf    []byte
+	mutex  sync.Mutex
+}
+
+// Write 实现io.Writer接口
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		log.Printf("%s%s", w.prefix, w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
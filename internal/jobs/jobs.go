@@ -0,0 +1,197 @@
+package jobs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/droid/go-mcp/internal/tools"
+	"github.com/google/uuid"
+)
+
+// DefaultTTL 已结束的任务保留的时间
+const DefaultTTL = 10 * time.Minute
+
+// Status 任务状态
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Job 一个异步执行的工具调用
+type Job struct {
+	ID          string                 `json:"id"`
+	Tool        string                 `json:"tool"`
+	Owner       string                 `json:"owner,omitempty"`
+	Status      Status                 `json:"status"`
+	Result      interface{}            `json:"result,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	Code        string                 `json:"code,omitempty"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time             `json:"expires_at,omitempty"`
+}
+
+// Done 判断任务是否已结束
+func (j Job) Done() bool {
+	return j.Status != StatusRunning
+}
+
+// RunFunc 执行任务的函数，ctx在任务被取消时取消
+type RunFunc func(ctx context.Context) tools.ToolResponse
+
+// entry 存储中的任务及其取消函数
+type entry struct {
+	job    Job
+	cancel context.CancelFunc
+}
+
+// Store 保存异步任务，已结束的任务在TTL到期后被清除
+type Store struct {
+	jobs  map[string]*entry
+	ttl   time.Duration
+	mutex sync.Mutex
+}
+
+// NewStore 创建任务存储，ttl为已结束任务的保留时间
+func NewStore(ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &Store{
+		jobs: make(map[string]*entry),
+		ttl:  ttl,
+	}
+}
+
+// Start 创建任务并在新的goroutine中执行run，任务结束后调用onDone（可以为nil）。
+// 任务的context派生自ctx，不随发起请求的连接结束而取消
+func (s *Store) Start(ctx context.Context, tool, owner string, run RunFunc, onDone func(Job)) Job {
+	jobCtx, cancel := context.WithCancel(ctx)
+
+	s.mutex.Lock()
+	s.prune()
+	e := &entry{
+		job: Job{
+			ID:        uuid.New().String(),
+			Tool:      tool,
+			Owner:     owner,
+			Status:    StatusRunning,
+			CreatedAt: time.Now(),
+		},
+		cancel: cancel,
+	}
+	s.jobs[e.job.ID] = e
+	job := e.job
+	s.mutex.Unlock()
+
+	go func() {
+		defer cancel()
+
+		finished := s.finish(e, run(jobCtx))
+		if onDone != nil {
+			onDone(finished)
+		}
+	}()
+
+	return job
+}
+
+// finish 记录任务结果，任务已被取消时不覆盖状态，返回结束后的任务
+func (s *Store) finish(e *entry, response tools.ToolResponse) Job {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if e.job.Status == StatusRunning {
+		switch {
+		case response.Status == "success":
+			e.job.Status = StatusSucceeded
+			e.job.Result = response.Content
+		case response.Code == tools.CodeCancelled:
+			e.job.Status = StatusCancelled
+		default:
+			e.job.Status = StatusFailed
+			e.job.Error = response.Error
+			e.job.Code = response.Code
+			e.job.Details = response.Details
+		}
+		s.complete(e)
+	}
+
+	return e.job
+}
+
+// complete 记录任务的结束时间和过期时间
+func (s *Store) complete(e *entry) {
+	now := time.Now()
+	expires := now.Add(s.ttl)
+	e.job.CompletedAt = &now
+	e.job.ExpiresAt = &expires
+}
+
+// Get 按ID获取任务
+func (s *Store) Get(id string) (Job, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.prune()
+	e, exists := s.jobs[id]
+	if !exists {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// List 返回按创建时间排序的任务，owner为空时返回所有任务
+func (s *Store) List(owner string) []Job {
+	s.mutex.Lock()
+	s.prune()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		if owner == "" || e.job.Owner == owner {
+			jobs = append(jobs, e.job)
+		}
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// Cancel 取消正在执行的任务，已结束的任务保持原状态；任务不存在时返回false
+func (s *Store) Cancel(id string) (Job, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.prune()
+	e, exists := s.jobs[id]
+	if !exists {
+		return Job{}, false
+	}
+
+	if e.job.Status == StatusRunning {
+		e.cancel()
+		e.job.Status = StatusCancelled
+		s.complete(e)
+	}
+	return e.job, true
+}
+
+// prune 清除已过期的任务，调用方需持有锁
+func (s *Store) prune() {
+	now := time.Now()
+	for id, e := range s.jobs {
+		if e.job.ExpiresAt != nil && now.After(*e.job.ExpiresAt) {
+			delete(s.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// startAndWait 启动任务并等待其结束，返回onDone收到的任务
+func startAndWait(t *testing.T, s *Store, owner string, response tools.ToolResponse) Job {
+	t.Helper()
+	done := make(chan Job, 1)
+	s.Start(context.Background(), "tool", owner, func(ctx context.Context) tools.ToolResponse {
+		return response
+	}, func(job Job) {
+		done <- job
+	})
+
+	select {
+	case job := <-done:
+		return job
+	case <-time.After(time.Second):
+		t.Fatalf("job did not finish")
+		return Job{}
+	}
+}
+
+func TestFinish(t *testing.T) {
+	tests := []struct {
+		name     string
+		response tools.ToolResponse
+		want     Job
+	}{
+		{
+			name:     "success",
+			response: tools.ToolResponse{Status: "success", Content: "ok"},
+			want:     Job{Status: StatusSucceeded, Result: "ok"},
+		},
+		{
+			name:     "tool error",
+			response: tools.ErrorResponse(tools.NewError(tools.CodeInvalidParams, "bad", map[string]interface{}{"field": "q"})),
+			want:     Job{Status: StatusFailed, Error: "bad", Code: tools.CodeInvalidParams},
+		},
+		{
+			name:     "cancelled by the tool",
+			response: tools.ErrorResponse(context.Canceled),
+			want:     Job{Status: StatusCancelled},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore(time.Minute)
+			job := startAndWait(t, s, "owner", tt.response)
+
+			if job.Status != tt.want.Status || job.Result != tt.want.Result ||
+				job.Error != tt.want.Error || job.Code != tt.want.Code {
+				t.Fatalf("job = %+v, want %+v", job, tt.want)
+			}
+			if job.CompletedAt == nil || job.ExpiresAt == nil || job.ExpiresAt.Sub(*job.CompletedAt) != time.Minute {
+				t.Fatalf("completed %v expires %v, want expiry one TTL after completion", job.CompletedAt, job.ExpiresAt)
+			}
+
+			stored, ok := s.Get(job.ID)
+			if !ok || stored.Status != tt.want.Status {
+				t.Fatalf("Get = %+v, %v, want the finished job", stored, ok)
+			}
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	s := NewStore(time.Minute)
+
+	started := make(chan struct{})
+	done := make(chan Job, 1)
+	job := s.Start(context.Background(), "slow", "owner", func(ctx context.Context) tools.ToolResponse {
+		close(started)
+		<-ctx.Done()
+		// 工具在取消后仍返回成功，状态不应被覆盖
+		return tools.ToolResponse{Status: "success", Content: "late"}
+	}, func(job Job) {
+		done <- job
+	})
+
+	if job.Status != StatusRunning || job.Done() {
+		t.Fatalf("started job = %+v, want running", job)
+	}
+	<-started
+
+	cancelled, ok := s.Cancel(job.ID)
+	if !ok || cancelled.Status != StatusCancelled || cancelled.CompletedAt == nil {
+		t.Fatalf("Cancel = %+v, %v, want cancelled job", cancelled, ok)
+	}
+
+	select {
+	case finished := <-done:
+		if finished.Status != StatusCancelled || finished.Result != nil {
+			t.Fatalf("onDone job = %+v, want cancelled without result", finished)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("run was not cancelled")
+	}
+
+	// 已结束的任务再次取消时保持原状态
+	finished := startAndWait(t, s, "owner", tools.ToolResponse{Status: "success", Content: "ok"})
+	again, ok := s.Cancel(finished.ID)
+	if !ok || again.Status != StatusSucceeded {
+		t.Fatalf("Cancel finished job = %+v, %v, want succeeded", again, ok)
+	}
+
+	if _, ok := s.Cancel("missing"); ok {
+		t.Fatalf("Cancel unknown job succeeded")
+	}
+}
+
+func TestPruneExpiredJobs(t *testing.T) {
+	const ttl = 50 * time.Millisecond
+	s := NewStore(ttl)
+
+	finished := startAndWait(t, s, "owner", tools.ToolResponse{Status: "success"})
+
+	release := make(chan struct{})
+	defer close(release)
+	running := s.Start(context.Background(), "slow", "owner", func(ctx context.Context) tools.ToolResponse {
+		<-release
+		return tools.ToolResponse{Status: "success"}
+	}, nil)
+
+	if _, ok := s.Get(finished.ID); !ok {
+		t.Fatalf("finished job pruned before its TTL")
+	}
+
+	time.Sleep(2 * ttl)
+
+	if _, ok := s.Get(finished.ID); ok {
+		t.Fatalf("finished job kept after its TTL")
+	}
+	if _, ok := s.Cancel(finished.ID); ok {
+		t.Fatalf("expired job can still be cancelled")
+	}
+
+	// 执行中的任务没有过期时间，不会被清除
+	jobs := s.List("owner")
+	if len(jobs) != 1 || jobs[0].ID != running.ID {
+		t.Fatalf("List = %+v, want only the running job", jobs)
+	}
+}
+
+func TestList(t *testing.T) {
+	s := NewStore(time.Minute)
+
+	first := startAndWait(t, s, "alice", tools.ToolResponse{Status: "success"})
+	second := startAndWait(t, s, "bob", tools.ToolResponse{Status: "success"})
+	third := startAndWait(t, s, "alice", tools.ToolResponse{Status: "success"})
+
+	tests := []struct {
+		owner string
+		want  []string
+	}{
+		{owner: "alice", want: []string{first.ID, third.ID}},
+		{owner: "bob", want: []string{second.ID}},
+		{owner: "carol"},
+		{owner: "", want: []string{first.ID, second.ID, third.ID}},
+	}
+
+	for _, tt := range tests {
+		jobs := s.List(tt.owner)
+		var got []string
+		for _, job := range jobs {
+			got = append(got, job.ID)
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("List(%q) = %v, want %v", tt.owner, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Fatalf("List(%q) = %v, want %v", tt.owner, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestNewStoreDefaultTTL(t *testing.T) {
+	job := startAndWait(t, NewStore(0), "owner", tools.ToolResponse{Status: "success"})
+	if job.ExpiresAt.Sub(*job.CompletedAt) != DefaultTTL {
+		t.Fatalf("TTL = %v, want %v", job.ExpiresAt.Sub(*job.CompletedAt), DefaultTTL)
+	}
+}
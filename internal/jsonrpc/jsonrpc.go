@@ -0,0 +1,156 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Version JSON-RPC协议版本
+const Version = "2.0"
+
+// 标准JSON-RPC错误码
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// 服务器自定义错误码（-32000到-32099）
+const (
+	CodeResourceNotFound     = -32002
+	CodeServerNotInitialized = -32003
+	CodePermissionDenied     = -32004
+	CodeServerBusy           = -32005
+)
+
+// Request 代表一个JSON-RPC请求或通知（没有ID时为通知）
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// IsNotification 判断请求是否为通知
+func (r *Request) IsNotification() bool {
+	return len(r.ID) == 0
+}
+
+// Response 代表一个JSON-RPC响应
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Notification 代表一个由服务器发出的JSON-RPC通知
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// Error 代表一个JSON-RPC错误对象
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// NewError 创建新的错误对象
+func NewError(code int, message string, data interface{}) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	}
+}
+
+// NewResponse 创建成功响应
+func NewResponse(id json.RawMessage, result interface{}) Response {
+	return Response{
+		JSONRPC: Version,
+		ID:      id,
+		Result:  result,
+	}
+}
+
+// NewErrorResponse 创建错误响应
+func NewErrorResponse(id json.RawMessage, err *Error) Response {
+	return Response{
+		JSONRPC: Version,
+		ID:      id,
+		Error:   err,
+	}
+}
+
+// NewNotification 创建通知
+func NewNotification(method string, params interface{}) Notification {
+	return Notification{
+		JSONRPC: Version,
+		Method:  method,
+		Params:  params,
+	}
+}
+
+// ParseRequest 解析并校验单个JSON-RPC请求
+func ParseRequest(data []byte) (Request, *Error) {
+	var request Request
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return request, NewError(CodeInvalidRequest, "Empty request", nil)
+	}
+
+	if err := json.Unmarshal(data, &request); err != nil {
+		if _, ok := err.(*json.UnmarshalTypeError); ok {
+			return request, NewError(CodeInvalidRequest, "Invalid request", err.Error())
+		}
+		return request, NewError(CodeParseError, "Parse error", err.Error())
+	}
+
+	if request.JSONRPC != Version {
+		return request, NewError(CodeInvalidRequest, "Invalid request", "jsonrpc must be \"2.0\"")
+	}
+
+	if request.Method == "" {
+		return request, NewError(CodeInvalidRequest, "Invalid request", "method is required")
+	}
+
+	// null ID 按规范不应使用，这里视为通知以外的非法请求
+	if bytes.Equal(request.ID, []byte("null")) {
+		return request, NewError(CodeInvalidRequest, "Invalid request", "id must not be null")
+	}
+
+	return request, nil
+}
+
+// IsBatch 判断消息是否为批量请求（JSON数组）
+func IsBatch(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '['
+}
+
+// ParseBatch 将批量请求拆分为单条消息，每条消息再由ParseRequest解析；
+// 空数组按规范视为非法请求
+func ParseBatch(data []byte) ([]json.RawMessage, *Error) {
+	var messages []json.RawMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, NewError(CodeParseError, "Parse error", err.Error())
+	}
+
+	if len(messages) == 0 {
+		return nil, NewError(CodeInvalidRequest, "Invalid request", "batch must not be empty")
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,285 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// importer 导入的工具共用的HTTP配置
+type importer struct {
+	baseURL string
+	headers map[string]string
+	prefix  string
+	client  *http.Client
+}
+
+// Option 配置OpenAPI导入
+type Option func(*importer)
+
+// WithBaseURL 设置请求的基础地址，默认使用文档中的第一个servers地址
+func WithBaseURL(baseURL string) Option {
+	return func(im *importer) {
+		im.baseURL = baseURL
+	}
+}
+
+// WithHeader 设置附加在每个请求上的请求头，如认证信息
+func WithHeader(key, value string) Option {
+	return func(im *importer) {
+		im.headers[key] = value
+	}
+}
+
+// WithPrefix 设置工具名前缀，用于区分多个服务的同名操作
+func WithPrefix(prefix string) Option {
+	return func(im *importer) {
+		im.prefix = prefix
+	}
+}
+
+// WithHTTPClient 设置发送请求的HTTP客户端
+func WithHTTPClient(client *http.Client) Option {
+	return func(im *importer) {
+		im.client = client
+	}
+}
+
+// toolNamePattern 工具名中不允许的字符
+var toolNamePattern = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// Tools 为文档中的每个操作创建一个工具，工具名为operationId，
+// 没有operationId时由HTTP方法和路径生成
+func Tools(doc *Document, opts ...Option) ([]*Tool, error) {
+	im := &importer{
+		headers: make(map[string]string),
+		client:  http.DefaultClient,
+	}
+	if len(doc.Servers) > 0 {
+		im.baseURL = doc.Servers[0].URL
+	}
+	for _, opt := range opts {
+		opt(im)
+	}
+
+	if !strings.HasPrefix(im.baseURL, "http://") && !strings.HasPrefix(im.baseURL, "https://") {
+		return nil, fmt.Errorf("base URL %q must be an absolute http(s) URL", im.baseURL)
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var result []*Tool
+	names := make(map[string]bool)
+	for _, path := range paths {
+		item := doc.Paths[path]
+		operations := item.operations()
+
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			tool, err := im.newTool(method, path, item.Parameters, operations[method])
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			if names[tool.name] {
+				return nil, fmt.Errorf("%s %s: duplicate tool name %s", method, path, tool.name)
+			}
+			names[tool.name] = true
+			result = append(result, tool)
+		}
+	}
+
+	return result, nil
+}
+
+// Register 读取OpenAPI文档并将所有操作注册为工具，返回注册的工具数。
+// 与已有工具同名的操作被跳过
+func Register(toolMgr *tools.ToolManager, path string, opts ...Option) (int, error) {
+	doc, err := Load(path)
+	if err != nil {
+		return 0, err
+	}
+
+	imported, err := Tools(doc, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+
+	count := 0
+	for _, tool := range imported {
+		if toolMgr.HasTool(tool.name) {
+			log.Printf("OpenAPI document %s: tool %s conflicts with an existing tool, skipped", path, tool.name)
+			continue
+		}
+		toolMgr.RegisterContextTool(tool)
+		count++
+	}
+
+	log.Printf("Imported %d tool(s) from OpenAPI document %s (%s)", count, path, doc.Info.Title)
+	return count, nil
+}
+
+// newTool 由一个操作创建工具，路径级参数可被操作中同名同位置的参数覆盖
+func (im *importer) newTool(method, path string, shared []Parameter, op *Operation) (*Tool, error) {
+	name := op.OperationID
+	if name == "" {
+		name = strings.ToLower(method) + "_" + strings.Trim(path, "/")
+	}
+	name = strings.Trim(toolNamePattern.ReplaceAllString(im.prefix+name, "_"), "_")
+
+	description := op.Summary
+	if description == "" {
+		description = op.Description
+	}
+	if description == "" {
+		description = method + " " + path
+	}
+	if op.Deprecated {
+		description += " (deprecated)"
+	}
+
+	tool := &Tool{
+		name:        name,
+		description: description,
+		method:      method,
+		path:        path,
+		importer:    im,
+	}
+
+	properties := make(map[string]interface{})
+	var required []string
+
+	for _, p := range mergeParameters(shared, op.Parameters) {
+		if p.In != "path" && p.In != "query" && p.In != "header" {
+			log.Printf("OpenAPI %s %s: %s parameter %s is not supported, ignored", method, path, p.In, p.Name)
+			continue
+		}
+
+		// 不同位置的同名参数以位置作为前缀区分
+		arg := p.Name
+		if _, exists := properties[arg]; exists || arg == BodyParam {
+			arg = p.In + "_" + p.Name
+		}
+
+		property, err := parameterSchema(p)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", p.Name, err)
+		}
+		properties[arg] = property
+		if p.Required || p.In == "path" {
+			required = append(required, arg)
+		}
+		tool.params = append(tool.params, param{arg: arg, name: p.Name, in: p.In})
+	}
+
+	if op.RequestBody != nil {
+		bodyType, bodySchema := requestBodySchema(op.RequestBody)
+		if bodyType == "" {
+			log.Printf("OpenAPI %s %s: request body has no supported media type, ignored", method, path)
+		} else {
+			tool.bodyType = bodyType
+			properties[BodyParam] = bodySchema
+			if op.RequestBody.Required {
+				required = append(required, BodyParam)
+			}
+		}
+	}
+
+	paramSchema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		paramSchema["required"] = required
+	}
+
+	data, err := json.Marshal(paramSchema)
+	if err != nil {
+		return nil, err
+	}
+	tool.schema = string(data)
+
+	return tool, nil
+}
+
+// mergeParameters 合并路径级参数和操作参数，按位置和名称去重
+func mergeParameters(shared, own []Parameter) []Parameter {
+	merged := make([]Parameter, 0, len(shared)+len(own))
+	overridden := make(map[string]bool, len(own))
+	for _, p := range own {
+		overridden[p.In+":"+p.Name] = true
+	}
+
+	for _, p := range shared {
+		if !overridden[p.In+":"+p.Name] {
+			merged = append(merged, p)
+		}
+	}
+	return append(merged, own...)
+}
+
+// parameterSchema 返回参数的schema，参数的描述合并到schema中
+func parameterSchema(p Parameter) (map[string]interface{}, error) {
+	property := map[string]interface{}{"type": "string"}
+	if len(p.Schema) > 0 {
+		property = make(map[string]interface{})
+		if err := json.Unmarshal(p.Schema, &property); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Description != "" {
+		property["description"] = p.Description
+	}
+	return property, nil
+}
+
+// requestBodySchema 选择请求体的媒体类型，优先使用JSON
+func requestBodySchema(body *RequestBody) (string, interface{}) {
+	types := make([]string, 0, len(body.Content))
+	for contentType := range body.Content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+
+	chosen := ""
+	for _, contentType := range types {
+		if isJSON(contentType) {
+			chosen = contentType
+			break
+		}
+		if contentType == "application/x-www-form-urlencoded" && chosen == "" {
+			chosen = contentType
+		}
+	}
+	if chosen == "" {
+		return "", nil
+	}
+
+	var bodySchema interface{} = map[string]interface{}{}
+	if raw := body.Content[chosen].Schema; len(raw) > 0 {
+		if err := json.Unmarshal(raw, &bodySchema); err != nil {
+			return "", nil
+		}
+	}
+	if object, ok := bodySchema.(map[string]interface{}); ok && body.Description != "" {
+		if _, exists := object["description"]; !exists {
+			object["description"] = body.Description
+		}
+	}
+	return chosen, bodySchema
+}
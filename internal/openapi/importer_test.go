@@ -0,0 +1,140 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// mustParse 解析测试文档
+func mustParse(t *testing.T, data string) *Document {
+	t.Helper()
+	doc, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return doc
+}
+
+// toolsByName 导入文档中的工具并按名称索引
+func toolsByName(t *testing.T, doc *Document, opts ...Option) map[string]*Tool {
+	t.Helper()
+	imported, err := Tools(doc, opts...)
+	if err != nil {
+		t.Fatalf("Tools: %v", err)
+	}
+	byName := make(map[string]*Tool, len(imported))
+	for _, tool := range imported {
+		byName[tool.Name()] = tool
+	}
+	return byName
+}
+
+func TestToolsNamesAndSchemas(t *testing.T) {
+	byName := toolsByName(t, mustParse(t, petstore))
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{"createPet", "delete_pets_petId", "getPet", "listPets", "status"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("tool names = %v, want %v", names, want)
+	}
+
+	tests := []struct {
+		tool       string
+		properties []string
+		required   []interface{}
+	}{
+		{tool: "listPets", properties: []string{"limit", "tags"}},
+		{tool: "createPet", properties: []string{"body"}, required: []interface{}{"body"}},
+		{tool: "getPet", properties: []string{"X-Trace", "petId"}, required: []interface{}{"petId"}},
+		{tool: "status", properties: []string{"code"}, required: []interface{}{"code"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			var schema struct {
+				Type       string                     `json:"type"`
+				Properties map[string]json.RawMessage `json:"properties"`
+				Required   []interface{}              `json:"required"`
+			}
+			if err := json.Unmarshal([]byte(byName[tt.tool].ParameterSchema()), &schema); err != nil {
+				t.Fatalf("schema: %v", err)
+			}
+
+			properties := make([]string, 0, len(schema.Properties))
+			for name := range schema.Properties {
+				properties = append(properties, name)
+			}
+			sort.Strings(properties)
+
+			if schema.Type != "object" || !reflect.DeepEqual(properties, tt.properties) {
+				t.Fatalf("schema = %s, want object with properties %v", byName[tt.tool].ParameterSchema(), tt.properties)
+			}
+			if !reflect.DeepEqual(schema.Required, tt.required) {
+				t.Fatalf("required = %v, want %v", schema.Required, tt.required)
+			}
+		})
+	}
+}
+
+func TestToolsPrefixAndBaseURL(t *testing.T) {
+	doc := mustParse(t, petstore)
+
+	byName := toolsByName(t, doc, WithPrefix("pets."))
+	if _, ok := byName["pets_listPets"]; !ok {
+		t.Fatalf("prefixed tools = %v, want pets_listPets", byName)
+	}
+
+	if _, err := Tools(doc, WithBaseURL("/relative")); err == nil {
+		t.Fatalf("Tools with relative base URL succeeded, want error")
+	}
+
+	doc.Servers = nil
+	if _, err := Tools(doc); err == nil {
+		t.Fatalf("Tools without servers succeeded, want error")
+	}
+}
+
+func TestRegisterSkipsExistingTools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "petstore.json")
+	if err := os.WriteFile(path, []byte(petstore), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	builtin := tools.NewTypedTool("listPets", "built-in tool",
+		func(ctx context.Context, params struct{}) (string, error) {
+			return "built-in", nil
+		})
+
+	toolMgr := tools.NewToolManager()
+	toolMgr.RegisterContextTool(builtin)
+
+	count, err := Register(toolMgr, path)
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if count != 4 {
+		t.Fatalf("Register count = %d, want 4", count)
+	}
+
+	response := toolMgr.ExecuteTool(context.Background(), tools.ToolRequest{
+		Name:       "listPets",
+		Parameters: json.RawMessage(`{}`),
+	})
+	if response.Status != "success" || !reflect.DeepEqual(tools.PlainValue(response.Content), "built-in") {
+		t.Fatalf("listPets response = %+v, want the built-in tool", response)
+	}
+	if !toolMgr.HasTool("getPet") {
+		t.Fatalf("getPet not registered")
+	}
+}
@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Document OpenAPI 3文档中导入工具所需的部分
+type Document struct {
+	OpenAPI string              `json:"openapi"`
+	Info    Info                `json:"info"`
+	Servers []Server            `json:"servers,omitempty"`
+	Paths   map[string]PathItem `json:"paths"`
+}
+
+// Info 文档信息
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Server 服务地址
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem 一个路径上的操作，Parameters为该路径下所有操作共用的参数
+type PathItem struct {
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Get        *Operation  `json:"get,omitempty"`
+	Put        *Operation  `json:"put,omitempty"`
+	Post       *Operation  `json:"post,omitempty"`
+	Delete     *Operation  `json:"delete,omitempty"`
+	Patch      *Operation  `json:"patch,omitempty"`
+	Head       *Operation  `json:"head,omitempty"`
+	Options    *Operation  `json:"options,omitempty"`
+}
+
+// operations 按HTTP方法返回路径上定义的操作
+func (p PathItem) operations() map[string]*Operation {
+	operations := map[string]*Operation{
+		"GET":     p.Get,
+		"PUT":     p.Put,
+		"POST":    p.Post,
+		"DELETE":  p.Delete,
+		"PATCH":   p.Patch,
+		"HEAD":    p.Head,
+		"OPTIONS": p.Options,
+	}
+	for method, op := range operations {
+		if op == nil {
+			delete(operations, method)
+		}
+	}
+	return operations
+}
+
+// Operation 一个API操作
+type Operation struct {
+	OperationID string       `json:"operationId,omitempty"`
+	Summary     string       `json:"summary,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Parameters  []Parameter  `json:"parameters,omitempty"`
+	RequestBody *RequestBody `json:"requestBody,omitempty"`
+	Deprecated  bool         `json:"deprecated,omitempty"`
+}
+
+// Parameter 路径、查询、请求头或cookie参数
+type Parameter struct {
+	Name        string          `json:"name"`
+	In          string          `json:"in"`
+	Description string          `json:"description,omitempty"`
+	Required    bool            `json:"required,omitempty"`
+	Schema      json.RawMessage `json:"schema,omitempty"`
+}
+
+// RequestBody 请求体，Content按媒体类型给出schema
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// MediaType 某一媒体类型的请求体
+type MediaType struct {
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
+
+// Load 读取JSON格式的OpenAPI 3文档，文档内的$ref引用被展开
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Parse 解析JSON格式的OpenAPI 3文档，文档内的$ref引用被展开
+func Parse(data []byte) (*Document, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	resolved, err := resolveRefs(raw, raw, make(map[string]bool))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, only 3.x is supported", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// resolveRefs 将文档内的 {"$ref": "#/..."} 替换为引用的内容，active为正在展开的引用，
+// 递归引用（如树形schema）在第二次出现时替换为不限制的schema
+func resolveRefs(value, root interface{}, active map[string]bool) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			if active[ref] {
+				return map[string]interface{}{}, nil
+			}
+			target, err := lookup(root, ref)
+			if err != nil {
+				return nil, err
+			}
+
+			active[ref] = true
+			defer delete(active, ref)
+			return resolveRefs(target, root, active)
+		}
+
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			r, err := resolveRefs(item, root, active)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = r
+		}
+		return resolved, nil
+
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			r, err := resolveRefs(item, root, active)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// lookup 按JSON Pointer查找文档内的引用
+func lookup(root interface{}, ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q, only references within the document are supported", ref)
+	}
+
+	value := root
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("$ref %q not found", ref)
+		}
+		if value, ok = object[token]; !ok {
+			return nil, fmt.Errorf("$ref %q not found", ref)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,127 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// petstore 测试用的OpenAPI文档，服务地址在各测试中替换
+const petstore = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Petstore", "version": "1.0"},
+  "servers": [{"url": "http://petstore.invalid/v1"}],
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "tags", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}},
+          {"name": "limit", "in": "query", "schema": {"type": "integer"}}
+        ]
+      },
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+        }
+      }
+    },
+    "/pets/{petId}": {
+      "parameters": [
+        {"$ref": "#/components/parameters/PetID"},
+        {"name": "X-Trace", "in": "header", "schema": {"type": "string"}}
+      ],
+      "get": {"operationId": "getPet"},
+      "delete": {"summary": "Delete a pet"}
+    },
+    "/status/{code}": {
+      "get": {
+        "operationId": "status",
+        "parameters": [{"name": "code", "in": "path", "schema": {"type": "integer"}}]
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "PetID": {"name": "petId", "in": "path", "required": true, "schema": {"type": "integer"}}
+    },
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "children": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}
+        },
+        "required": ["name"]
+      }
+    }
+  }
+}`
+
+func TestParseExpandsRefs(t *testing.T) {
+	doc, err := Parse([]byte(petstore))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	shared := doc.Paths["/pets/{petId}"].Parameters
+	if len(shared) != 2 || shared[0].Name != "petId" || shared[0].In != "path" || !shared[0].Required {
+		t.Fatalf("shared parameters = %+v, want petId path parameter expanded from $ref", shared)
+	}
+
+	var body map[string]interface{}
+	raw := doc.Paths["/pets"].Post.RequestBody.Content["application/json"].Schema
+	if err := json.Unmarshal(raw, &body); err != nil {
+		t.Fatalf("request body schema: %v", err)
+	}
+	if body["type"] != "object" {
+		t.Fatalf("request body schema = %v, want Pet schema expanded from $ref", body)
+	}
+
+	// Pet中对自身的递归引用替换为不限制的schema
+	children := body["properties"].(map[string]interface{})["children"].(map[string]interface{})
+	if got := children["items"]; !reflect.DeepEqual(got, map[string]interface{}{}) {
+		t.Fatalf("recursive reference = %v, want empty schema", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{
+			name: "swagger 2",
+			doc:  `{"swagger": "2.0", "paths": {}}`,
+			want: "unsupported OpenAPI version",
+		},
+		{
+			name: "external ref",
+			doc:  `{"openapi": "3.0.0", "paths": {"/a": {"get": {"requestBody": {"$ref": "other.json#/Body"}}}}}`,
+			want: "only references within the document",
+		},
+		{
+			name: "missing ref",
+			doc:  `{"openapi": "3.0.0", "paths": {"/a": {"get": {"requestBody": {"$ref": "#/components/requestBodies/Missing"}}}}}`,
+			want: "not found",
+		},
+		{
+			name: "invalid json",
+			doc:  `{"openapi": `,
+			want: "unexpected end of JSON input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Parse error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// maxResponseSize 读取的响应体最大字节数
+const maxResponseSize = 10 * 1024 * 1024 // 10MB
+
+// maxErrorBody 错误详情中保留的响应体最大字节数
+const maxErrorBody = 4096
+
+// BodyParam 请求体在工具参数中的名称
+const BodyParam = "body"
+
+// param 工具参数与HTTP参数的对应关系
+type param struct {
+	arg  string // 工具参数名
+	name string // HTTP参数名
+	in   string // path、query或header
+}
+
+// Tool 调用一个OpenAPI操作的工具，实现tools.ContextTool
+type Tool struct {
+	name        string
+	description string
+	schema      string
+	method      string
+	path        string
+	params      []param
+	bodyType    string // 请求体的媒体类型，为空表示没有请求体
+	importer    *importer
+}
+
+// Name 返回工具名称
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description 返回工具描述
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// ParameterSchema 返回由操作参数和请求体生成的JSON Schema
+func (t *Tool) ParameterSchema() string {
+	return t.schema
+}
+
+// ExecuteContext 按参数构建HTTP请求并返回响应，JSON响应被解析后返回
+func (t *Tool) ExecuteContext(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	// 数字保留原始写法，避免1000000格式化为1e+06或大整数在请求体中丢失精度
+	var args map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.UseNumber()
+	if err := decoder.Decode(&args); err != nil {
+		return nil, tools.Errorf(tools.CodeInvalidParams, "Invalid parameters for tool %s: %v", t.name, err)
+	}
+
+	request, err := t.newRequest(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := t.importer.client.Do(request)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, tools.Errorf(tools.CodeExecutionFailed, "Request to %s failed: %v", request.URL.Redacted(), err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxResponseSize))
+	if err != nil {
+		return nil, tools.Errorf(tools.CodeExecutionFailed, "Failed to read response: %v", err)
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		if len(body) > maxErrorBody {
+			body = body[:maxErrorBody]
+		}
+		return nil, tools.NewError(tools.CodeExecutionFailed,
+			fmt.Sprintf("%s %s returned %s", t.method, t.path, response.Status),
+			map[string]interface{}{
+				"status": response.StatusCode,
+				"body":   string(body),
+			})
+	}
+
+	if isJSON(response.Header.Get("Content-Type")) && len(bytes.TrimSpace(body)) > 0 {
+		var result interface{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, tools.Errorf(tools.CodeExecutionFailed, "Invalid JSON response: %v", err)
+		}
+		return result, nil
+	}
+	return string(body), nil
+}
+
+// newRequest 构建HTTP请求：路径参数替换到路径中，查询参数和请求头参数按位置设置，
+// body参数序列化为请求体，导入时配置的请求头附加在每个请求上
+func (t *Tool) newRequest(ctx context.Context, args map[string]interface{}) (*http.Request, error) {
+	path := t.path
+	query := url.Values{}
+	header := http.Header{}
+
+	for _, p := range t.params {
+		value, ok := args[p.arg]
+		if !ok || value == nil {
+			continue
+		}
+
+		switch p.in {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.name+"}", url.PathEscape(formatValue(value)))
+		case "query":
+			if values, ok := value.([]interface{}); ok {
+				for _, v := range values {
+					query.Add(p.name, formatValue(v))
+				}
+			} else {
+				query.Set(p.name, formatValue(value))
+			}
+		case "header":
+			header.Set(p.name, formatValue(value))
+		}
+	}
+
+	target := strings.TrimRight(t.importer.baseURL, "/") + path
+	if encoded := query.Encode(); encoded != "" {
+		target += "?" + encoded
+	}
+
+	var body io.Reader
+	if value, ok := args[BodyParam]; ok && t.bodyType != "" {
+		data, err := encodeBody(t.bodyType, value)
+		if err != nil {
+			return nil, tools.Errorf(tools.CodeInvalidParams, "Invalid request body: %v", err)
+		}
+		body = bytes.NewReader(data)
+		header.Set("Content-Type", t.bodyType)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, t.method, target, body)
+	if err != nil {
+		return nil, tools.Errorf(tools.CodeExecutionFailed, "Invalid request: %v", err)
+	}
+
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	for key, value := range t.importer.headers {
+		request.Header.Set(key, value)
+	}
+	if request.Header.Get("Accept") == "" {
+		request.Header.Set("Accept", "application/json")
+	}
+
+	return request, nil
+}
+
+// encodeBody 按媒体类型序列化请求体，表单类型的请求体必须是对象
+func encodeBody(contentType string, value interface{}) ([]byte, error) {
+	if contentType != "application/x-www-form-urlencoded" {
+		return json.Marshal(value)
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("form body must be an object")
+	}
+
+	form := url.Values{}
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		form.Set(key, formatValue(object[key]))
+	}
+	return []byte(form.Encode()), nil
+}
+
+// formatValue 将参数值格式化为字符串，数字不使用指数形式，对象和数组格式化为JSON
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		// 整数保持原样，其他数字统一为不带指数的十进制写法
+		if _, err := v.Int64(); err == nil {
+			return v.String()
+		}
+		if f, err := v.Float64(); err == nil {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// isJSON 判断媒体类型是否为JSON
+func isJSON(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
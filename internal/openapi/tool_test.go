@@ -0,0 +1,201 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// recorded 测试服务器收到的请求
+type recorded struct {
+	Method      string              `json:"method"`
+	Path        string              `json:"path"`
+	Query       map[string][]string `json:"query"`
+	Trace       string              `json:"trace"`
+	Auth        string              `json:"auth"`
+	ContentType string              `json:"contentType"`
+	Body        string              `json:"body"`
+}
+
+// newPetServer 启动测试服务器：/status/{code}返回对应状态码，其他路径以JSON回显请求
+func newPetServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if code := strings.TrimPrefix(r.URL.Path, "/v1/status/"); code != r.URL.Path {
+			status, _ := strconv.Atoi(code)
+			w.WriteHeader(status)
+			io.WriteString(w, "status "+code)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(recorded{
+			Method:      r.Method,
+			Path:        r.URL.EscapedPath(),
+			Query:       r.URL.Query(),
+			Trace:       r.Header.Get("X-Trace"),
+			Auth:        r.Header.Get("Authorization"),
+			ContentType: r.Header.Get("Content-Type"),
+			Body:        string(body),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// execute 执行工具并将回显的请求解析为recorded
+func execute(t *testing.T, tool *Tool, params string) recorded {
+	t.Helper()
+	result, err := tool.ExecuteContext(context.Background(), json.RawMessage(params))
+	if err != nil {
+		t.Fatalf("ExecuteContext: %v", err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got recorded
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("response %s: %v", data, err)
+	}
+	return got
+}
+
+func TestExecuteMapsParameters(t *testing.T) {
+	server := newPetServer(t)
+	byName := toolsByName(t, mustParse(t, petstore),
+		WithBaseURL(server.URL+"/v1"),
+		WithHeader("Authorization", "Bearer secret"))
+
+	tests := []struct {
+		name   string
+		tool   string
+		params string
+		want   recorded
+	}{
+		{
+			name:   "path and header",
+			tool:   "getPet",
+			params: `{"petId": 1000000, "X-Trace": "abc"}`,
+			want:   recorded{Method: "GET", Path: "/v1/pets/1000000", Query: map[string][]string{}, Trace: "abc"},
+		},
+		{
+			name:   "escaped path",
+			tool:   "getPet",
+			params: `{"petId": "a/b"}`,
+			want:   recorded{Method: "GET", Path: "/v1/pets/a%2Fb", Query: map[string][]string{}},
+		},
+		{
+			name:   "query array and number",
+			tool:   "listPets",
+			params: `{"tags": ["cat", "dog"], "limit": 2.5e7}`,
+			want: recorded{Method: "GET", Path: "/v1/pets", Query: map[string][]string{
+				"tags":  {"cat", "dog"},
+				"limit": {"25000000"},
+			}},
+		},
+		{
+			name:   "omitted optional parameters",
+			tool:   "listPets",
+			params: `{}`,
+			want:   recorded{Method: "GET", Path: "/v1/pets", Query: map[string][]string{}},
+		},
+		{
+			name:   "json body",
+			tool:   "createPet",
+			params: `{"body": {"name": "Rex", "age": 12345678901234567890}}`,
+			want: recorded{Method: "POST", Path: "/v1/pets", Query: map[string][]string{},
+				ContentType: "application/json", Body: `{"age":12345678901234567890,"name":"Rex"}`},
+		},
+		{
+			name:   "generated name",
+			tool:   "delete_pets_petId",
+			params: `{"petId": 7}`,
+			want:   recorded{Method: "DELETE", Path: "/v1/pets/7", Query: map[string][]string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := execute(t, byName[tt.tool], tt.params)
+			tt.want.Auth = "Bearer secret"
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("request = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteErrorStatus(t *testing.T) {
+	server := newPetServer(t)
+	status := toolsByName(t, mustParse(t, petstore), WithBaseURL(server.URL+"/v1"))["status"]
+
+	tests := []struct {
+		code    int
+		wantErr bool
+	}{
+		{code: 200},
+		{code: 302},
+		{code: 400, wantErr: true},
+		{code: 404, wantErr: true},
+		{code: 500, wantErr: true},
+		{code: 503, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.code), func(t *testing.T) {
+			// 302没有Location，客户端直接返回该响应
+			result, err := status.ExecuteContext(context.Background(),
+				json.RawMessage(`{"code": `+strconv.Itoa(tt.code)+`}`))
+
+			if !tt.wantErr {
+				if err != nil || result != "status "+strconv.Itoa(tt.code) {
+					t.Fatalf("result = %v, %v, want text response", result, err)
+				}
+				return
+			}
+
+			var toolErr *tools.Error
+			if !errors.As(err, &toolErr) {
+				t.Fatalf("error = %v, want *tools.Error", err)
+			}
+			if toolErr.Code != tools.CodeExecutionFailed {
+				t.Fatalf("code = %s, want %s", toolErr.Code, tools.CodeExecutionFailed)
+			}
+			if toolErr.Details["status"] != tt.code || toolErr.Details["body"] != "status "+strconv.Itoa(tt.code) {
+				t.Fatalf("details = %v, want status %d and body", toolErr.Details, tt.code)
+			}
+		})
+	}
+}
+
+func TestExecuteUsesDocumentServer(t *testing.T) {
+	server := newPetServer(t)
+	doc := mustParse(t, strings.Replace(petstore, "http://petstore.invalid/v1", server.URL+"/v1", 1))
+
+	got := execute(t, toolsByName(t, doc)["listPets"], `{"limit": 5}`)
+	if got.Path != "/v1/pets" || !reflect.DeepEqual(got.Query["limit"], []string{"5"}) {
+		t.Fatalf("request = %+v, want /v1/pets?limit=5 on the document server", got)
+	}
+}
+
+func TestExecuteInvalidParams(t *testing.T) {
+	tool := toolsByName(t, mustParse(t, petstore))["listPets"]
+
+	_, err := tool.ExecuteContext(context.Background(), json.RawMessage(`[1, 2]`))
+	var toolErr *tools.Error
+	if !errors.As(err, &toolErr) || toolErr.Code != tools.CodeInvalidParams {
+		t.Fatalf("error = %v, want %s", err, tools.CodeInvalidParams)
+	}
+}
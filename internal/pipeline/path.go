@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 表达式的根
+const (
+	rootInputs = "inputs"
+	rootSteps  = "steps"
+)
+
+// segment 路径中的一段，key为对象字段，index为数组下标
+type segment struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// expr 引用管道输入或前序步骤输出的表达式，如 $.inputs.query、
+// $.steps[0].results[0].content 或 $.steps.search.results
+type expr struct {
+	source string
+	root   string
+	step   int // root为steps时引用的步骤下标
+	path   []segment
+}
+
+// isExpr 判断字符串是否为表达式。以 $$ 开头的字符串是转义的字面量
+func isExpr(s string) bool {
+	return strings.HasPrefix(s, "$.")
+}
+
+// parseExpr 解析表达式，stepIDs为可引用的步骤ID到下标的映射
+func parseExpr(source string, stepIDs map[string]int) (*expr, error) {
+	if !isExpr(source) {
+		return nil, fmt.Errorf("expression %q must start with $.", source)
+	}
+
+	path, err := parsePath(source[1:])
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", source, err)
+	}
+	if len(path) == 0 || !path[0].isKey {
+		return nil, fmt.Errorf("expression %q must start with $.inputs or $.steps", source)
+	}
+
+	e := &expr{source: source, root: path[0].key}
+	switch e.root {
+	case rootInputs:
+		e.path = path[1:]
+
+	case rootSteps:
+		if len(path) < 2 {
+			return nil, fmt.Errorf("expression %q must reference a step", source)
+		}
+		ref := path[1]
+		if ref.isKey {
+			index, ok := stepIDs[ref.key]
+			if !ok {
+				return nil, fmt.Errorf("expression %q references unknown step %q", source, ref.key)
+			}
+			e.step = index
+		} else {
+			e.step = ref.index
+		}
+		e.path = path[2:]
+
+	default:
+		return nil, fmt.Errorf("expression %q must start with $.inputs or $.steps", source)
+	}
+
+	return e, nil
+}
+
+// parsePath 解析 .name 和 [n] 组成的路径
+func parsePath(s string) ([]segment, error) {
+	var path []segment
+
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty field name")
+			}
+			path = append(path, segment{key: s[:end], isKey: true})
+			s = s[end:]
+
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index")
+			}
+			index, err := strconv.Atoi(s[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q", s[1:end])
+			}
+			path = append(path, segment{index: index})
+			s = s[end+1:]
+
+		default:
+			return nil, fmt.Errorf("unexpected %q", s[0])
+		}
+	}
+
+	return path, nil
+}
+
+// eval 计算表达式的值
+func (e *expr) eval(inputs map[string]interface{}, outputs []interface{}) (interface{}, error) {
+	var value interface{} = inputs
+	if e.root == rootSteps {
+		if e.step >= len(outputs) {
+			return nil, fmt.Errorf("%s: step %d has not run", e.source, e.step)
+		}
+		value = outputs[e.step]
+	}
+
+	for _, seg := range e.path {
+		if seg.isKey {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: cannot read field %q of %s", e.source, seg.key, typeName(value))
+			}
+			if value, ok = object[seg.key]; !ok {
+				return nil, fmt.Errorf("%s: field %q not found", e.source, seg.key)
+			}
+			continue
+		}
+
+		array, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: cannot index %s", e.source, typeName(value))
+		}
+		if seg.index >= len(array) {
+			return nil, fmt.Errorf("%s: index %d out of range (length %d)", e.source, seg.index, len(array))
+		}
+		value = array[seg.index]
+	}
+
+	return value, nil
+}
+
+// typeName 返回值的JSON类型名称，用于错误信息
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	default:
+		return "number"
+	}
+}
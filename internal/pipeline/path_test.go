@@ -0,0 +1,221 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// formatPath 将路径格式化为 .name 和 [n] 的形式，便于比较
+func formatPath(path []segment) string {
+	var sb strings.Builder
+	for _, seg := range path {
+		if seg.isKey {
+			sb.WriteString("." + seg.key)
+		} else {
+			fmt.Fprintf(&sb, "[%d]", seg.index)
+		}
+	}
+	return sb.String()
+}
+
+func TestParseExpr(t *testing.T) {
+	stepIDs := map[string]int{"search": 0, "fetch": 1}
+
+	tests := []struct {
+		source string
+		root   string
+		step   int
+		path   string
+		err    string
+	}{
+		{source: "$.inputs", root: rootInputs},
+		{source: "$.inputs.query", root: rootInputs, path: ".query"},
+		{source: "$.inputs.filters[2].name", root: rootInputs, path: ".filters[2].name"},
+		{source: "$.steps[1]", root: rootSteps, step: 1},
+		{source: "$.steps[0].results[10].content", root: rootSteps, step: 0, path: ".results[10].content"},
+		{source: "$.steps.fetch.body", root: rootSteps, step: 1, path: ".body"},
+		{source: "$.steps.search[0][1]", root: rootSteps, step: 0, path: "[0][1]"},
+		{source: "$.inputs.a-b.c_d", root: rootInputs, path: ".a-b.c_d"},
+
+		{source: "inputs.query", err: "must start with $."},
+		{source: "$inputs", err: "must start with $."},
+		{source: "$.other.query", err: "must start with $.inputs or $.steps"},
+		{source: "$.[0]", err: "empty field name"},
+		{source: "$.steps", err: "must reference a step"},
+		{source: "$.steps.missing", err: `unknown step "missing"`},
+		{source: "$.inputs..query", err: "empty field name"},
+		{source: "$.inputs.", err: "empty field name"},
+		{source: "$.inputs.list[1", err: "unterminated index"},
+		{source: "$.inputs.list[-1]", err: `invalid index "-1"`},
+		{source: "$.inputs.list[x]", err: `invalid index "x"`},
+		{source: "$.inputs.list[0]x", err: `unexpected 'x'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			e, err := parseExpr(tt.source, stepIDs)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("parseExpr error = %v, want containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseExpr: %v", err)
+			}
+			if e.root != tt.root || e.step != tt.step || formatPath(e.path) != tt.path {
+				t.Fatalf("parseExpr = root %s step %d path %q, want root %s step %d path %q",
+					e.root, e.step, formatPath(e.path), tt.root, tt.step, tt.path)
+			}
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	stepIDs := map[string]int{"search": 0}
+	inputs := map[string]interface{}{
+		"query": "golang",
+		"tags":  []interface{}{"a", "b"},
+		"empty": nil,
+	}
+	outputs := []interface{}{
+		map[string]interface{}{
+			"results": []interface{}{
+				map[string]interface{}{"title": "Go", "score": 0.9},
+			},
+		},
+	}
+
+	tests := []struct {
+		source string
+		want   interface{}
+		err    string
+	}{
+		{source: "$.inputs.query", want: "golang"},
+		{source: "$.inputs.tags[1]", want: "b"},
+		{source: "$.inputs.empty", want: nil},
+		{source: "$.inputs", want: inputs},
+		{source: "$.steps[0].results[0].title", want: "Go"},
+		{source: "$.steps.search.results[0].score", want: 0.9},
+
+		{source: "$.inputs.missing", err: `field "missing" not found`},
+		{source: "$.inputs.tags[2]", err: "index 2 out of range (length 2)"},
+		{source: "$.inputs.query.length", err: `cannot read field "length" of string`},
+		{source: "$.inputs.query[0]", err: "cannot index string"},
+		{source: "$.inputs.empty.x", err: `cannot read field "x" of null`},
+		{source: "$.steps[0].results.title", err: `cannot read field "title" of array`},
+		{source: "$.steps[1]", err: "step 1 has not run"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			e, err := parseExpr(tt.source, stepIDs)
+			if err != nil {
+				t.Fatalf("parseExpr: %v", err)
+			}
+
+			got, err := e.eval(inputs, outputs)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("eval error = %v, want containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("eval: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("eval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileParams(t *testing.T) {
+	declared := map[string]bool{"query": true}
+	stepIDs := map[string]int{"first": 0, "second": 1}
+
+	tests := []struct {
+		name    string
+		params  interface{}
+		current int
+		want    interface{} // 表达式替换为其源文本后的结果
+		err     string
+	}{
+		{
+			name:   "nested expressions",
+			params: map[string]interface{}{"q": "$.inputs.query", "list": []interface{}{"$.inputs.query", 1.0}},
+			want:   map[string]interface{}{"q": "expr:$.inputs.query", "list": []interface{}{"expr:$.inputs.query", 1.0}},
+		},
+		{
+			name:   "escaped literal",
+			params: map[string]interface{}{"price": "$$.inputs.query", "plain": "$5"},
+			want:   map[string]interface{}{"price": "$.inputs.query", "plain": "$5"},
+		},
+		{
+			name:    "earlier step",
+			params:  "$.steps.first.id",
+			current: 1,
+			want:    "expr:$.steps.first.id",
+		},
+		{
+			name:   "undeclared input",
+			params: "$.inputs.other",
+			err:    `undeclared input "other"`,
+		},
+		{
+			name:    "same step",
+			params:  "$.steps[1]",
+			current: 1,
+			err:     "must reference an earlier step",
+		},
+		{
+			name:    "later step by id",
+			params:  map[string]interface{}{"x": []interface{}{"$.steps.second"}},
+			current: 1,
+			err:     "must reference an earlier step",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := compile(tt.params, declared, stepIDs, tt.current)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("compile error = %v, want containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			if got := describe(compiled); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("compile = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// describe 将编译结果中的表达式替换为 "expr:" 加源文本
+func describe(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *expr:
+		return "expr:" + v.source
+	case map[string]interface{}:
+		described := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			described[key] = describe(item)
+		}
+		return described
+	case []interface{}:
+		described := make([]interface{}, len(v))
+		for i, item := range v {
+			described[i] = describe(item)
+		}
+		return described
+	default:
+		return v
+	}
+}
@@ -0,0 +1,452 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/droid/go-mcp/internal/schema"
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// MaxDepth 管道嵌套调用的最大深度，防止管道直接或间接调用自身
+const MaxDepth = 8
+
+// Input 管道的输入参数，用于生成管道工具的ParameterSchema
+type Input struct {
+	Name        string        `json:"name"`
+	Type        string        `json:"type,omitempty"` // 默认为string
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+}
+
+// Step 管道中的一个步骤。Params中以 $. 开头的字符串是表达式，执行时替换为
+// 引用的值：$.inputs.<参数> 引用管道输入，$.steps[<下标>] 或 $.steps.<步骤ID>
+// 引用前序步骤的输出，其后可跟 .字段 和 [下标]。以 $$ 开头的字符串表示以 $ 开头的字面量
+type Step struct {
+	ID     string                 `json:"id,omitempty"`
+	Tool   string                 `json:"tool"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// Definition 声明式管道定义，可以在Go中构造或从JSON文件加载
+type Definition struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Inputs      []Input `json:"inputs,omitempty"`
+	Steps       []Step  `json:"steps"`
+
+	// Output 管道结果的表达式，为空时返回最后一个步骤的输出
+	Output string `json:"output,omitempty"`
+}
+
+// compiledStep 参数中的表达式已解析的步骤
+type compiledStep struct {
+	id     string
+	tool   string
+	params interface{}
+}
+
+// Pipeline 由多个工具调用组成的复合工具，实现tools.ContextTool
+type Pipeline struct {
+	name        string
+	description string
+	schema      string
+	steps       []compiledStep
+	output      *expr
+	toolMgr     *tools.ToolManager
+}
+
+// depthKey context中保存管道嵌套深度的键
+type depthKey struct{}
+
+// New 编译管道定义，步骤中的工具通过toolMgr执行
+func New(toolMgr *tools.ToolManager, def Definition) (*Pipeline, error) {
+	if def.Name == "" {
+		return nil, errors.New("pipeline name is required")
+	}
+	if len(def.Steps) == 0 {
+		return nil, fmt.Errorf("pipeline %s: at least one step is required", def.Name)
+	}
+
+	paramSchema, inputs, err := inputSchema(def.Inputs)
+	if err != nil {
+		return nil, fmt.Errorf("pipeline %s: %w", def.Name, err)
+	}
+
+	p := &Pipeline{
+		name:        def.Name,
+		description: def.Description,
+		schema:      paramSchema,
+		steps:       make([]compiledStep, 0, len(def.Steps)),
+		toolMgr:     toolMgr,
+	}
+
+	stepIDs := make(map[string]int)
+	for i, step := range def.Steps {
+		if step.Tool == "" {
+			return nil, fmt.Errorf("pipeline %s: step %d: tool is required", def.Name, i)
+		}
+		if step.Tool == def.Name {
+			return nil, fmt.Errorf("pipeline %s: step %d calls the pipeline itself", def.Name, i)
+		}
+
+		params, err := compile(step.Params, inputs, stepIDs, i)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %s: step %d: %w", def.Name, i, err)
+		}
+		p.steps = append(p.steps, compiledStep{id: step.ID, tool: step.Tool, params: params})
+
+		if step.ID != "" {
+			if _, exists := stepIDs[step.ID]; exists {
+				return nil, fmt.Errorf("pipeline %s: duplicate step id %q", def.Name, step.ID)
+			}
+			stepIDs[step.ID] = i
+		}
+	}
+
+	if def.Output != "" {
+		output, err := compileExpr(def.Output, inputs, stepIDs, len(def.Steps))
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %s: output: %w", def.Name, err)
+		}
+		p.output = output
+	}
+
+	return p, nil
+}
+
+// Register 编译管道定义并注册为工具
+func Register(toolMgr *tools.ToolManager, def Definition) error {
+	p, err := New(toolMgr, def)
+	if err != nil {
+		return err
+	}
+
+	toolMgr.RegisterContextTool(p)
+	return nil
+}
+
+// LoadDir 加载目录下所有 .json 管道定义文件并注册为工具
+func LoadDir(toolMgr *tools.ToolManager, dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := LoadFile(toolMgr, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadFile 从JSON文件加载一个管道定义并注册为工具
+func LoadFile(toolMgr *tools.ToolManager, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var def Definition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := Register(toolMgr, def); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// inputSchema 根据输入参数生成ParameterSchema，同时返回输入参数名集合
+func inputSchema(inputs []Input) (string, map[string]bool, error) {
+	s := &schema.Schema{
+		Type:       schema.Types{schema.TypeObject},
+		Properties: make(map[string]*schema.Schema, len(inputs)),
+	}
+	names := make(map[string]bool, len(inputs))
+
+	for _, input := range inputs {
+		if input.Name == "" {
+			return "", nil, errors.New("input name is required")
+		}
+		if names[input.Name] {
+			return "", nil, fmt.Errorf("duplicate input %q", input.Name)
+		}
+		names[input.Name] = true
+
+		inputType := input.Type
+		if inputType == "" {
+			inputType = schema.TypeString
+		}
+
+		s.Properties[input.Name] = &schema.Schema{
+			Type:        schema.Types{inputType},
+			Description: input.Description,
+			Default:     input.Default,
+			Enum:        input.Enum,
+		}
+		if input.Required {
+			s.Required = append(s.Required, input.Name)
+		}
+	}
+
+	return s.String(), names, nil
+}
+
+// compile 解析参数中的表达式，current为当前步骤下标，只能引用之前的步骤
+func compile(value interface{}, inputs map[string]bool, stepIDs map[string]int, current int) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "$$") {
+			return v[1:], nil
+		}
+		if isExpr(v) {
+			return compileExpr(v, inputs, stepIDs, current)
+		}
+		return v, nil
+
+	case map[string]interface{}:
+		compiled := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			c, err := compile(item, inputs, stepIDs, current)
+			if err != nil {
+				return nil, err
+			}
+			compiled[key] = c
+		}
+		return compiled, nil
+
+	case []interface{}:
+		compiled := make([]interface{}, len(v))
+		for i, item := range v {
+			c, err := compile(item, inputs, stepIDs, current)
+			if err != nil {
+				return nil, err
+			}
+			compiled[i] = c
+		}
+		return compiled, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// compileExpr 解析表达式并检查其引用的输入参数和步骤是否存在
+func compileExpr(source string, inputs map[string]bool, stepIDs map[string]int, current int) (*expr, error) {
+	e, err := parseExpr(source, stepIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.root {
+	case rootInputs:
+		if len(e.path) > 0 && e.path[0].isKey && !inputs[e.path[0].key] {
+			return nil, fmt.Errorf("expression %q references undeclared input %q", source, e.path[0].key)
+		}
+	case rootSteps:
+		if e.step >= current {
+			return nil, fmt.Errorf("expression %q must reference an earlier step", source)
+		}
+	}
+
+	return e, nil
+}
+
+// omitted 判断表达式是否引用了调用方省略的可选输入（声明的输入已在编译时检查）
+func (e *expr) omitted(inputs map[string]interface{}) bool {
+	if e.root != rootInputs || len(e.path) == 0 || !e.path[0].isKey {
+		return false
+	}
+	_, exists := inputs[e.path[0].key]
+	return !exists
+}
+
+// render 计算参数中的表达式，返回可以序列化为工具参数的值。引用省略的可选输入的
+// 表达式为null，作为对象字段时该字段被省略，由步骤的工具使用其默认值
+func render(value interface{}, inputs map[string]interface{}, outputs []interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case *expr:
+		if v.omitted(inputs) {
+			return nil, nil
+		}
+		return v.eval(inputs, outputs)
+
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if e, ok := item.(*expr); ok && e.omitted(inputs) {
+				continue
+			}
+			r, err := render(item, inputs, outputs)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = r
+		}
+		return rendered, nil
+
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			r, err := render(item, inputs, outputs)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = r
+		}
+		return rendered, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// Name 返回工具名称
+func (p *Pipeline) Name() string {
+	return p.name
+}
+
+// Description 返回工具描述
+func (p *Pipeline) Description() string {
+	return p.description
+}
+
+// ParameterSchema 返回由输入参数生成的JSON Schema
+func (p *Pipeline) ParameterSchema() string {
+	return p.schema
+}
+
+// ExecuteContext 依次执行各步骤，每个步骤完成后报告进度。
+// 步骤失败时返回的错误详情中step为失败步骤的下标
+func (p *Pipeline) ExecuteContext(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	depth, _ := ctx.Value(depthKey{}).(int)
+	if depth >= MaxDepth {
+		return nil, tools.Errorf(tools.CodeExecutionFailed, "Pipeline %s exceeds the maximum nesting depth of %d", p.name, MaxDepth)
+	}
+
+	inputs := make(map[string]interface{})
+	if err := json.Unmarshal(params, &inputs); err != nil {
+		return nil, tools.Errorf(tools.CodeInvalidParams, "Invalid parameters for pipeline %s: %v", p.name, err)
+	}
+
+	// 步骤中的工具不向调用方报告进度，管道按步骤报告
+	progress := tools.ProgressFromContext(ctx)
+	stepCtx := context.WithValue(ctx, depthKey{}, depth+1)
+	stepCtx = tools.WithProgressReporter(stepCtx, tools.ProgressFunc(func(float64, float64, string) {}))
+
+	total := float64(len(p.steps))
+	outputs := make([]interface{}, 0, len(p.steps))
+	for i, step := range p.steps {
+		progress.Report(float64(i), total, fmt.Sprintf("Running step %d (%s)", i, step.tool))
+
+		rendered, err := render(step.params, inputs, outputs)
+		if err != nil {
+			return nil, p.stepError(i, step, err.Error(), "", nil)
+		}
+
+		stepParams, err := json.Marshal(rendered)
+		if err != nil {
+			return nil, p.stepError(i, step, err.Error(), "", nil)
+		}
+
+		response := p.toolMgr.ExecuteTool(stepCtx, tools.ToolRequest{
+			Name:       step.tool,
+			Parameters: stepParams,
+		})
+		if response.Status != "success" {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return nil, p.stepError(i, step, response.Error, response.Code, response.Details)
+		}
+
+		output, err := outputValue(response.Content)
+		if err != nil {
+			return nil, p.stepError(i, step, err.Error(), "", nil)
+		}
+		outputs = append(outputs, output)
+	}
+	progress.Report(total, total, "Done")
+
+	if p.output != nil {
+		result, err := render(p.output, inputs, outputs)
+		if err != nil {
+			return nil, tools.Errorf(tools.CodeExecutionFailed, "Pipeline %s output: %v", p.name, err)
+		}
+		return result, nil
+	}
+	return outputs[len(outputs)-1], nil
+}
+
+// stepError 构建步骤失败的错误，详情中包含失败步骤的下标、工具以及步骤的错误码和详情
+func (p *Pipeline) stepError(index int, step compiledStep, message, code string, details map[string]interface{}) error {
+	errDetails := map[string]interface{}{
+		"step": index,
+		"tool": step.tool,
+	}
+	if step.id != "" {
+		errDetails["step_id"] = step.id
+	}
+	if code != "" {
+		errDetails["step_code"] = code
+	}
+	if len(details) > 0 {
+		errDetails["step_details"] = details
+	}
+
+	return tools.NewError(tools.CodeExecutionFailed,
+		fmt.Sprintf("Pipeline %s failed at step %d (%s): %s", p.name, index, step.tool, message),
+		errDetails)
+}
+
+// outputValue 将步骤的结果转换为可被表达式引用的JSON值：带structuredContent的
+// 结果取structuredContent，只有内容块的结果取其文本，其他结果按JSON转换
+func outputValue(content interface{}) (interface{}, error) {
+	switch v := content.(type) {
+	case *tools.Result:
+		if v == nil {
+			return nil, nil
+		}
+		if v.StructuredContent != nil {
+			return normalize(v.StructuredContent)
+		}
+		texts := make([]string, 0, len(v.Content))
+		for _, block := range v.Content {
+			if block.Type == tools.ContentText {
+				texts = append(texts, block.Text)
+			}
+		}
+		return strings.Join(texts, "\n"), nil
+
+	case tools.Result:
+		return outputValue(&v)
+
+	default:
+		return normalize(v)
+	}
+}
+
+// normalize 将任意值转换为map[string]interface{}、[]interface{}等通用JSON值
+func normalize(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
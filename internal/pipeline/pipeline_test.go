@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// echoParams 测试工具的参数，format省略时使用工具自身的默认值
+type echoParams struct {
+	Query  string `json:"query"`
+	Format string `json:"format,omitempty" default:"text"`
+}
+
+// newEchoManager 创建注册了echo工具的工具管理器，echo原样返回收到的参数
+func newEchoManager() *tools.ToolManager {
+	toolMgr := tools.NewToolManager()
+	toolMgr.RegisterContextTool(tools.NewTypedTool("echo", "Return the parameters",
+		func(ctx context.Context, params echoParams) (echoParams, error) {
+			return params, nil
+		}))
+	return toolMgr
+}
+
+func TestExecuteOmittedOptionalInput(t *testing.T) {
+	inputs := []Input{
+		{Name: "query", Required: true},
+		{Name: "format"},
+	}
+
+	tests := []struct {
+		name   string
+		steps  []Step
+		output string
+		want   interface{}
+	}{
+		{
+			name: "object field dropped",
+			steps: []Step{
+				{Tool: "echo", Params: map[string]interface{}{"query": "$.inputs.query", "format": "$.inputs.format"}},
+			},
+			want: map[string]interface{}{"query": "go", "format": "text"},
+		},
+		{
+			name: "output is null",
+			steps: []Step{
+				{Tool: "echo", Params: map[string]interface{}{"query": "$.inputs.query"}},
+			},
+			output: "$.inputs.format",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(newEchoManager(), Definition{Name: "p", Inputs: inputs, Steps: tt.steps, Output: tt.output})
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			got, err := p.ExecuteContext(context.Background(), json.RawMessage(`{"query": "go"}`))
+			if err != nil {
+				t.Fatalf("ExecuteContext: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExecuteContext = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
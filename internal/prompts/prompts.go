@@ -0,0 +1,162 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/droid/go-mcp/internal/resources"
+)
+
+// ErrNotFound 提示词不存在
+var ErrNotFound = errors.New("prompt not found")
+
+// 消息角色
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Argument 描述提示词参数
+type Argument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// Prompt 描述一个提示词
+type Prompt struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Arguments   []Argument `json:"arguments,omitempty"`
+}
+
+// Content 提示词消息内容，文本使用Text，嵌入资源使用Resource
+type Content struct {
+	Type     string              `json:"type"`
+	Text     string              `json:"text,omitempty"`
+	Resource *resources.Contents `json:"resource,omitempty"`
+}
+
+// Message 提示词渲染后的一条消息
+type Message struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// Result prompts/get的结果
+type Result struct {
+	Description string    `json:"description,omitempty"`
+	Messages    []Message `json:"messages"`
+}
+
+// TextMessage 创建文本消息
+func TextMessage(role, text string) Message {
+	return Message{
+		Role:    role,
+		Content: Content{Type: "text", Text: text},
+	}
+}
+
+// ResourceMessage 创建嵌入资源的消息
+func ResourceMessage(role string, contents resources.Contents) Message {
+	return Message{
+		Role:    role,
+		Content: Content{Type: "resource", Resource: &contents},
+	}
+}
+
+// Handler 根据参数渲染提示词消息
+type Handler func(ctx context.Context, args map[string]string) ([]Message, error)
+
+// ArgumentError 参数错误
+type ArgumentError struct {
+	Prompt   string
+	Argument string
+}
+
+// Error 实现error接口
+func (e *ArgumentError) Error() string {
+	return fmt.Sprintf("prompt %s: missing required argument %s", e.Prompt, e.Argument)
+}
+
+// entry 注册表中的提示词
+type entry struct {
+	prompt  Prompt
+	handler Handler
+}
+
+// Registry 管理提示词
+type Registry struct {
+	prompts map[string]entry
+	resMgr  *resources.Manager
+	mutex   sync.RWMutex
+}
+
+// NewRegistry 创建新的提示词注册表，resMgr用于解析模板中嵌入的资源，可以为nil
+func NewRegistry(resMgr *resources.Manager) *Registry {
+	return &Registry{
+		prompts: make(map[string]entry),
+		resMgr:  resMgr,
+	}
+}
+
+// Register 注册一个提示词及其渲染函数
+func (r *Registry) Register(prompt Prompt, handler Handler) {
+	r.mutex.Lock()
+	r.prompts[prompt.Name] = entry{prompt: prompt, handler: handler}
+	r.mutex.Unlock()
+
+	log.Printf("Prompt registered: %s", prompt.Name)
+}
+
+// List 返回按名称排序的提示词列表
+func (r *Registry) List() []Prompt {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	prompts := make([]Prompt, 0, len(r.prompts))
+	for _, e := range r.prompts {
+		prompts = append(prompts, e.prompt)
+	}
+
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].Name < prompts[j].Name
+	})
+
+	return prompts
+}
+
+// Get 校验参数并渲染提示词
+func (r *Registry) Get(ctx context.Context, name string, args map[string]string) (*Result, error) {
+	r.mutex.RLock()
+	e, exists := r.prompts[name]
+	r.mutex.RUnlock()
+
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	if args == nil {
+		args = make(map[string]string)
+	}
+
+	for _, arg := range e.prompt.Arguments {
+		if arg.Required && args[arg.Name] == "" {
+			return nil, &ArgumentError{Prompt: name, Argument: arg.Name}
+		}
+	}
+
+	messages, err := e.handler(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Description: e.prompt.Description,
+		Messages:    messages,
+	}, nil
+}
@@ -0,0 +1,163 @@
+package prompts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Template 声明式提示词定义，可以在Go中构造或从JSON文件加载
+type Template struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Arguments   []Argument        `json:"arguments,omitempty"`
+	Messages    []MessageTemplate `json:"messages"`
+}
+
+// MessageTemplate 消息模板，Text为文本模板，Resource为要嵌入的资源URI模板，
+// 模板中通过 {{.参数名}} 引用参数
+type MessageTemplate struct {
+	Role     string `json:"role"`
+	Text     string `json:"text,omitempty"`
+	Resource string `json:"resource,omitempty"`
+}
+
+// compiledMessage 编译后的消息模板
+type compiledMessage struct {
+	role     string
+	text     *template.Template
+	resource *template.Template
+}
+
+// RegisterTemplate 编译并注册模板提示词
+func (r *Registry) RegisterTemplate(t Template) error {
+	if t.Name == "" {
+		return errors.New("prompt name is required")
+	}
+	if len(t.Messages) == 0 {
+		return fmt.Errorf("prompt %s: at least one message is required", t.Name)
+	}
+
+	messages := make([]compiledMessage, 0, len(t.Messages))
+	for i, m := range t.Messages {
+		role := m.Role
+		if role == "" {
+			role = RoleUser
+		}
+		if role != RoleUser && role != RoleAssistant {
+			return fmt.Errorf("prompt %s: message %d has invalid role %q", t.Name, i, m.Role)
+		}
+
+		if (m.Text == "") == (m.Resource == "") {
+			return fmt.Errorf("prompt %s: message %d must set exactly one of text or resource", t.Name, i)
+		}
+
+		compiled := compiledMessage{role: role}
+		source, target := m.Text, &compiled.text
+		if m.Resource != "" {
+			source, target = m.Resource, &compiled.resource
+		}
+
+		tmpl, err := template.New(fmt.Sprintf("%s[%d]", t.Name, i)).Option("missingkey=zero").Parse(source)
+		if err != nil {
+			return fmt.Errorf("prompt %s: %w", t.Name, err)
+		}
+		*target = tmpl
+
+		messages = append(messages, compiled)
+	}
+
+	r.Register(Prompt{
+		Name:        t.Name,
+		Description: t.Description,
+		Arguments:   t.Arguments,
+	}, func(ctx context.Context, args map[string]string) ([]Message, error) {
+		return r.renderTemplate(ctx, messages, args)
+	})
+
+	return nil
+}
+
+// LoadDir 加载目录下所有 .json 提示词模板文件
+func (r *Registry) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := r.LoadFile(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadFile 从JSON文件加载一个提示词模板
+func (r *Registry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var t Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := r.RegisterTemplate(t); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// renderTemplate 渲染模板消息，嵌入的资源通过资源管理器读取
+func (r *Registry) renderTemplate(ctx context.Context, messages []compiledMessage, args map[string]string) ([]Message, error) {
+	rendered := make([]Message, 0, len(messages))
+
+	for _, m := range messages {
+		if m.text != nil {
+			text, err := execute(m.text, args)
+			if err != nil {
+				return nil, err
+			}
+			rendered = append(rendered, TextMessage(m.role, text))
+			continue
+		}
+
+		uri, err := execute(m.resource, args)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.resMgr == nil {
+			return nil, fmt.Errorf("cannot embed resource %s: no resource manager", uri)
+		}
+
+		contents, err := r.resMgr.ReadResource(ctx, uri)
+		if err != nil {
+			return nil, fmt.Errorf("embed resource %s: %w", uri, err)
+		}
+
+		for _, c := range contents {
+			rendered = append(rendered, ResourceMessage(m.role, c))
+		}
+	}
+
+	return rendered, nil
+}
+
+// execute 使用参数执行模板
+func execute(tmpl *template.Template, args map[string]string) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, args); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,169 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+)
+
+// ErrNotFound 资源不存在，提供者不拥有该URI时也返回此错误
+var ErrNotFound = errors.New("resource not found")
+
+// Resource 描述一个可读取的资源
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplate 描述一类资源的URI模板
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// Contents 资源内容，文本资源使用Text，二进制资源使用base64编码的Blob
+type Contents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// Provider 定义资源提供者接口
+type Provider interface {
+	// ListResources 返回提供者拥有的资源
+	ListResources(ctx context.Context) ([]Resource, error)
+
+	// ListResourceTemplates 返回提供者支持的资源模板
+	ListResourceTemplates(ctx context.Context) ([]ResourceTemplate, error)
+
+	// ReadResource 读取资源内容，URI不属于该提供者时返回ErrNotFound
+	ReadResource(ctx context.Context, uri string) ([]Contents, error)
+}
+
+// ChangeKind 资源变更类型
+type ChangeKind int
+
+const (
+	// ChangeUpdated 资源内容发生变化
+	ChangeUpdated ChangeKind = iota
+	// ChangeListChanged 资源列表发生变化（新增或删除）
+	ChangeListChanged
+)
+
+// Change 描述一次资源变更
+type Change struct {
+	Kind ChangeKind
+	URI  string
+}
+
+// ChangeNotifier 由内容会变化的提供者实现，注册时管理器通过OnChange订阅其变更
+type ChangeNotifier interface {
+	OnChange(handler func(Change))
+}
+
+// Manager 汇总多个资源提供者
+type Manager struct {
+	providers []Provider
+	watchers  []func(Change)
+	mutex     sync.RWMutex
+}
+
+// NewManager 创建新的资源管理器
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// RegisterProvider 注册一个资源提供者
+func (m *Manager) RegisterProvider(provider Provider) {
+	m.mutex.Lock()
+	m.providers = append(m.providers, provider)
+	m.mutex.Unlock()
+
+	if notifier, ok := provider.(ChangeNotifier); ok {
+		notifier.OnChange(m.notify)
+	}
+
+	log.Printf("Resource provider registered: %T", provider)
+
+	// 已有观察者时，新的提供者意味着资源列表发生变化
+	m.notify(Change{Kind: ChangeListChanged})
+}
+
+// Watch 注册资源变更观察者
+func (m *Manager) Watch(watcher func(Change)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.watchers = append(m.watchers, watcher)
+}
+
+// notify 将变更分发给所有观察者
+func (m *Manager) notify(change Change) {
+	m.mutex.RLock()
+	watchers := make([]func(Change), len(m.watchers))
+	copy(watchers, m.watchers)
+	m.mutex.RUnlock()
+
+	for _, watcher := range watchers {
+		watcher(change)
+	}
+}
+
+// ListResources 返回所有提供者的资源
+func (m *Manager) ListResources(ctx context.Context) ([]Resource, error) {
+	resources := make([]Resource, 0)
+
+	for _, provider := range m.snapshot() {
+		items, err := provider.ListResources(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, items...)
+	}
+
+	return resources, nil
+}
+
+// ListResourceTemplates 返回所有提供者的资源模板
+func (m *Manager) ListResourceTemplates(ctx context.Context) ([]ResourceTemplate, error) {
+	templates := make([]ResourceTemplate, 0)
+
+	for _, provider := range m.snapshot() {
+		items, err := provider.ListResourceTemplates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, items...)
+	}
+
+	return templates, nil
+}
+
+// ReadResource 依次询问提供者读取资源
+func (m *Manager) ReadResource(ctx context.Context, uri string) ([]Contents, error) {
+	for _, provider := range m.snapshot() {
+		contents, err := provider.ReadResource(ctx, uri)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		return contents, err
+	}
+
+	return nil, ErrNotFound
+}
+
+// snapshot 返回当前提供者列表的副本
+func (m *Manager) snapshot() []Provider {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	providers := make([]Provider, len(m.providers))
+	copy(providers, m.providers)
+	return providers
+}
@@ -0,0 +1,68 @@
+package schema
+
+// ApplyDefaults 为对象中缺失的属性填充schema声明的默认值，返回填充后的值。
+// oneOf/anyOf只应用第一个匹配分支的默认值，值应由Decode解码
+func (s *Schema) ApplyDefaults(value interface{}) interface{} {
+	if s == nil || s.never {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, property := range s.Properties {
+			current, exists := v[name]
+			if !exists {
+				if property.Default == nil {
+					continue
+				}
+				// 默认值被schema的所有调用共享，插入副本以免被修改
+				current = cloneValue(property.Default)
+			}
+			v[name] = property.ApplyDefaults(current)
+		}
+
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				v[i] = s.Items.ApplyDefaults(item)
+			}
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		value = sub.ApplyDefaults(value)
+	}
+
+	for _, branches := range [][]*Schema{s.OneOf, s.AnyOf} {
+		for _, branch := range branches {
+			if len(branch.Validate(value)) == 0 {
+				value = branch.ApplyDefaults(value)
+				break
+			}
+		}
+	}
+
+	return value
+}
+
+// cloneValue 深拷贝JSON值中的对象和数组
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			clone[key] = cloneValue(item)
+		}
+		return clone
+
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = cloneValue(item)
+		}
+		return clone
+
+	default:
+		return value
+	}
+}
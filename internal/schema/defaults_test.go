@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   string
+	}{
+		{
+			name:   "missing property",
+			schema: `{"properties": {"limit": {"type": "integer", "default": 10}}}`,
+			value:  `{}`,
+			want:   `{"limit":10}`,
+		},
+		{
+			name:   "present property kept",
+			schema: `{"properties": {"limit": {"type": "integer", "default": 10}}}`,
+			value:  `{"limit": 3}`,
+			want:   `{"limit":3}`,
+		},
+		{
+			name:   "nested object default",
+			schema: `{"properties": {"options": {"default": {}, "properties": {"depth": {"default": 1}}}}}`,
+			value:  `{}`,
+			want:   `{"options":{"depth":1}}`,
+		},
+		{
+			name:   "array items",
+			schema: `{"items": {"properties": {"on": {"default": true}}}}`,
+			value:  `[{}, {"on": false}]`,
+			want:   `[{"on":true},{"on":false}]`,
+		},
+		{
+			name:   "allOf",
+			schema: `{"allOf": [{"properties": {"a": {"default": 1}}}, {"properties": {"b": {"default": 2}}}]}`,
+			value:  `{}`,
+			want:   `{"a":1,"b":2}`,
+		},
+		{
+			name: "first matching oneOf branch",
+			schema: `{"oneOf": [
+				{"properties": {"action": {"const": "get"}, "id": {"default": 0}}, "required": ["action"]},
+				{"properties": {"action": {"const": "list"}, "limit": {"default": 20}}, "required": ["action"]}
+			]}`,
+			value: `{"action": "list"}`,
+			want:  `{"action":"list","limit":20}`,
+		},
+		{
+			name:   "non-object value",
+			schema: `{"properties": {"limit": {"default": 10}}}`,
+			value:  `"text"`,
+			want:   `"text"`,
+		},
+		{
+			name:   "false schema",
+			schema: `false`,
+			value:  `{}`,
+			want:   `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := mustParse(t, tt.schema).ApplyDefaults(mustDecode(t, tt.value))
+			got, err := json.Marshal(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("ApplyDefaults = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDefaultsCopiesDefaults(t *testing.T) {
+	s := mustParse(t, `{"properties": {"filters": {"default": {"tags": ["a"]}}}}`)
+
+	first := s.ApplyDefaults(mustDecode(t, `{}`)).(map[string]interface{})
+	filters := first["filters"].(map[string]interface{})
+	filters["tags"] = append(filters["tags"].([]interface{}), "b")
+	filters["extra"] = true
+
+	second, err := json.Marshal(s.ApplyDefaults(mustDecode(t, `{}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(second) != `{"filters":{"tags":["a"]}}` {
+		t.Fatalf("second call = %s, want the unmodified default", second)
+	}
+}
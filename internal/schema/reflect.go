@@ -0,0 +1,309 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 生成schema时读取的结构体标签：
+//
+//	json        属性名，"-"表示忽略；未声明omitempty的字段为必填
+//	description 属性描述
+//	enum        逗号分隔的可选值，切片字段作用于元素
+//	default     默认值，按字段类型解析，复杂类型使用JSON
+//	minimum     数值下限
+//	maximum     数值上限
+//	pattern     字符串正则
+//	format      字符串格式，如date-time
+const (
+	tagDescription = "description"
+	tagEnum        = "enum"
+	tagDefault     = "default"
+	tagMinimum     = "minimum"
+	tagMaximum     = "maximum"
+	tagPattern     = "pattern"
+	tagFormat      = "format"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Reflect 根据Go值的类型生成JSON Schema，通常传入参数结构体的零值
+func Reflect(v interface{}) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("cannot generate schema for nil")
+	}
+	return reflectType(t, map[reflect.Type]bool{})
+}
+
+// MustReflect 与Reflect相同，类型不受支持时panic，用于初始化包级变量
+func MustReflect(v interface{}) *Schema {
+	s, err := Reflect(v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Variant oneOf的一个分支，以区分属性的取值选择参数结构体
+type Variant struct {
+	Value       string
+	Description string
+	Params      interface{}
+}
+
+// Discriminated 生成按区分属性（如action）选择分支的oneOf schema，
+// 每个分支由参数结构体生成，并加入必填的区分属性。顶层声明为object，
+// 满足MCP对inputSchema的要求
+func Discriminated(property string, variants ...Variant) (*Schema, error) {
+	s := &Schema{Type: Types{TypeObject}}
+	for _, variant := range variants {
+		branch, err := Reflect(variant.Params)
+		if err != nil {
+			return nil, fmt.Errorf("variant %q: %w", variant.Value, err)
+		}
+		if branch.Properties == nil {
+			return nil, fmt.Errorf("variant %q: params must be a struct", variant.Value)
+		}
+
+		branch.Properties[property] = &Schema{
+			Type:        Types{TypeString},
+			Enum:        []interface{}{variant.Value},
+			Description: variant.Description,
+		}
+		branch.Required = append([]string{property}, branch.Required...)
+		s.OneOf = append(s.OneOf, branch)
+	}
+	return s, nil
+}
+
+// MustDiscriminated 与Discriminated相同，失败时panic
+func MustDiscriminated(property string, variants ...Variant) *Schema {
+	s, err := Discriminated(property, variants...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// String 返回schema的JSON文本，可直接作为工具的ParameterSchema
+func (s *Schema) String() string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// reflectType 递归生成类型的schema，visiting用于检测递归类型
+func reflectType(t reflect.Type, visiting map[reflect.Type]bool) (*Schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: Types{TypeString}, Format: "date-time"}, nil
+	case rawMessageType:
+		return &Schema{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{TypeBoolean}}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{TypeInteger}}, nil
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{TypeNumber}}, nil
+
+	case reflect.String:
+		return &Schema{Type: Types{TypeString}}, nil
+
+	case reflect.Interface:
+		return &Schema{}, nil
+
+	case reflect.Slice, reflect.Array:
+		// encoding/json将[]byte编码为base64字符串
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{TypeString}}, nil
+		}
+		items, err := reflectType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: Types{TypeArray}, Items: items}, nil
+
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := reflectType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: Types{TypeObject}, AdditionalProperties: values}, nil
+
+	case reflect.Struct:
+		if visiting[t] {
+			return nil, fmt.Errorf("recursive type %s is not supported", t)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s := &Schema{Type: Types{TypeObject}, Properties: map[string]*Schema{}}
+		if err := reflectFields(s, t, visiting); err != nil {
+			return nil, err
+		}
+		return s, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// reflectFields 将结构体字段加入对象schema，匿名嵌入的结构体字段会被展开
+func reflectFields(s *Schema, t reflect.Type, visiting map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := reflectFields(s, embedded, visiting); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		property, err := reflectType(field.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if err := applyTags(property, field); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		s.Properties[name] = property
+		if !omitEmpty {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+// jsonName 解析字段的json标签，返回属性名、是否omitempty及是否忽略该字段
+func jsonName(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	omitEmpty := false
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+// applyTags 将字段的扩展标签应用到属性schema
+func applyTags(s *Schema, field reflect.StructField) error {
+	s.Description = field.Tag.Get(tagDescription)
+	if format := field.Tag.Get(tagFormat); format != "" {
+		s.Format = format
+	}
+	s.Pattern = field.Tag.Get(tagPattern)
+
+	for _, bound := range []struct {
+		tag    string
+		target **float64
+	}{
+		{tagMinimum, &s.Minimum},
+		{tagMaximum, &s.Maximum},
+	} {
+		value := field.Tag.Get(bound.tag)
+		if value == "" {
+			continue
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag %q", bound.tag, value)
+		}
+		*bound.target = &number
+	}
+
+	if value, exists := field.Tag.Lookup(tagDefault); exists {
+		parsed, err := parseTagValue(field.Type, value)
+		if err != nil {
+			return fmt.Errorf("invalid default tag %q: %w", value, err)
+		}
+		s.Default = parsed
+	}
+
+	if value := field.Tag.Get(tagEnum); value != "" {
+		// 切片字段的枚举值约束元素
+		target, elemType := s, field.Type
+		if s.Items != nil {
+			target, elemType = s.Items, field.Type.Elem()
+		}
+
+		for _, option := range strings.Split(value, ",") {
+			parsed, err := parseTagValue(elemType, strings.TrimSpace(option))
+			if err != nil {
+				return fmt.Errorf("invalid enum tag %q: %w", value, err)
+			}
+			target.Enum = append(target.Enum, parsed)
+		}
+	}
+
+	return nil
+}
+
+// parseTagValue 按字段类型解析标签中的值
+func parseTagValue(t reflect.Type, value string) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return value, nil
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseInt(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	default:
+		return Decode([]byte(value))
+	}
+}
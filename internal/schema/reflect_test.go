@@ -0,0 +1,142 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type reflectBase struct {
+	Page int `json:"page,omitempty" default:"1" minimum:"1"`
+}
+
+type reflectParams struct {
+	reflectBase
+	Query    string            `json:"query" description:"Search text" pattern:"^\\S+$"`
+	Mode     string            `json:"mode,omitempty" enum:"fast, exact" default:"fast"`
+	Tags     []string          `json:"tags,omitempty" enum:"a,b"`
+	Ratio    float64           `json:"ratio,omitempty" maximum:"1.5"`
+	Since    *time.Time        `json:"since,omitempty"`
+	Labels   map[string]int    `json:"labels,omitempty"`
+	Raw      json.RawMessage   `json:"raw,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	Options  map[string]string `json:"options,omitempty" default:"{\"k\":\"v\"}"`
+	Ignored  string            `json:"-"`
+	internal string
+	NoTag    bool
+}
+
+type recursiveParams struct {
+	Children []recursiveParams `json:"children"`
+}
+
+func TestReflect(t *testing.T) {
+	s, err := Reflect(reflectParams{})
+	if err != nil {
+		t.Fatalf("Reflect: %v", err)
+	}
+
+	got, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"object","properties":{` +
+		`"NoTag":{"type":"boolean"},` +
+		`"data":{"type":"string"},` +
+		`"labels":{"type":"object","additionalProperties":{"type":"integer"}},` +
+		`"mode":{"type":"string","enum":["fast","exact"],"default":"fast"},` +
+		`"options":{"type":"object","default":{"k":"v"},"additionalProperties":{"type":"string"}},` +
+		`"page":{"type":"integer","default":1,"minimum":1},` +
+		`"query":{"type":"string","description":"Search text","pattern":"^\\S+$"},` +
+		`"ratio":{"type":"number","maximum":1.5},` +
+		`"raw":{},` +
+		`"since":{"type":"string","format":"date-time"},` +
+		`"tags":{"type":"array","items":{"type":"string","enum":["a","b"]}}` +
+		`},"required":["query","NoTag"]}`
+	if string(got) != want {
+		t.Fatalf("Reflect =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestReflectErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "nil", value: nil, want: "nil"},
+		{name: "recursive", value: recursiveParams{}, want: "recursive type"},
+		{name: "map key", value: map[int]string{}, want: "unsupported map key"},
+		{name: "channel", value: struct{ C chan int }{}, want: "field C: unsupported type"},
+		{name: "bad default", value: struct {
+			N int `default:"x"`
+		}{}, want: "invalid default tag"},
+		{name: "bad minimum", value: struct {
+			N int `minimum:"low"`
+		}{}, want: "invalid minimum tag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Reflect(tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Reflect error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscriminated(t *testing.T) {
+	type getParams struct {
+		ID int `json:"id"`
+	}
+	type listParams struct {
+		Limit int `json:"limit,omitempty" default:"20"`
+	}
+
+	s, err := Discriminated("action",
+		Variant{Value: "get", Description: "Get one item", Params: getParams{}},
+		Variant{Value: "list", Params: listParams{}},
+	)
+	if err != nil {
+		t.Fatalf("Discriminated: %v", err)
+	}
+
+	// MCP要求inputSchema顶层为object
+	if len(s.Type) != 1 || s.Type[0] != TypeObject {
+		t.Fatalf("type = %v, want object", s.Type)
+	}
+	if len(s.OneOf) != 2 {
+		t.Fatalf("oneOf has %d branches, want 2", len(s.OneOf))
+	}
+	if required := s.OneOf[0].Required; len(required) != 2 || required[0] != "action" || required[1] != "id" {
+		t.Fatalf("first branch required = %v, want [action id]", required)
+	}
+
+	tests := []struct {
+		value string
+		want  string // 违规项的pointer:keyword，为空表示通过
+	}{
+		{value: `{"action": "get", "id": 1}`},
+		{value: `{"action": "list"}`},
+		{value: `{"action": "get"}`, want: "/id:required"},
+		{value: `{"action": "remove"}`, want: "/action:enum"},
+		{value: `"get"`, want: ":type"},
+	}
+	for _, tt := range tests {
+		violations := s.Validate(mustDecode(t, tt.value))
+		got := ""
+		if len(violations) > 0 {
+			last := violations[len(violations)-1]
+			got = last.Pointer + ":" + last.Keyword
+		}
+		if got != tt.want {
+			t.Errorf("Validate(%s) = %+v, want %q", tt.value, violations, tt.want)
+		}
+	}
+
+	if _, err := Discriminated("action", Variant{Value: "bad", Params: "not a struct"}); err == nil {
+		t.Fatalf("Discriminated with non-struct params succeeded")
+	}
+}
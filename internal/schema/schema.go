@@ -0,0 +1,140 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// 支持的JSON类型
+const (
+	TypeNull    = "null"
+	TypeBoolean = "boolean"
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeString  = "string"
+)
+
+// Types JSON Schema的type关键字，可以是单个类型或类型数组
+type Types []string
+
+// UnmarshalJSON 支持字符串和字符串数组两种形式
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = multiple
+	return nil
+}
+
+// MarshalJSON 单个类型输出为字符串
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Schema 表示一个JSON Schema，支持工具参数常用的关键字子集
+type Schema struct {
+	Type        Types  `json:"type,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Format      string `json:"format,omitempty"`
+
+	Enum    []interface{} `json:"enum,omitempty"`
+	Const   interface{}   `json:"const,omitempty"`
+	Default interface{}   `json:"default,omitempty"`
+
+	// 对象
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	// 数组
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	// 字符串
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+
+	// 数值
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	// 组合
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	AllOf []*Schema `json:"allOf,omitempty"`
+
+	// never 对应布尔schema false，任何值都不匹配
+	never bool
+}
+
+// schemaAlias 用于避免MarshalJSON/UnmarshalJSON递归
+type schemaAlias Schema
+
+// UnmarshalJSON 支持布尔schema：true匹配任何值，false不匹配任何值
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{never: true}
+		return nil
+	}
+
+	var alias schemaAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	*s = Schema(alias)
+	return nil
+}
+
+// MarshalJSON 布尔schema false输出为false
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.never {
+		return []byte("false"), nil
+	}
+	return json.Marshal((*schemaAlias)(s))
+}
+
+// False 返回不匹配任何值的schema，常用于禁止额外属性
+func False() *Schema {
+	return &Schema{never: true}
+}
+
+// Parse 解析JSON Schema字符串
+func Parse(data string) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Decode 以保留数字精度的方式解码JSON值，供Validate和ApplyDefaults使用
+func Decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,420 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Violation 描述一处校验失败，Pointer为出错位置的JSON Pointer
+type Violation struct {
+	Pointer string `json:"pointer"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+// Validate 校验值是否符合schema，返回所有违规项；值应由Decode解码
+func (s *Schema) Validate(value interface{}) []Violation {
+	return s.validate(value, "")
+}
+
+// validate 递归校验，pointer为当前位置
+func (s *Schema) validate(value interface{}, pointer string) []Violation {
+	if s == nil {
+		return nil
+	}
+
+	if s.never {
+		return []Violation{violation(pointer, "false", "value is not allowed")}
+	}
+
+	// 类型不匹配时不再检查其他关键字，避免产生无意义的违规项
+	if len(s.Type) > 0 && !matchesAnyType(value, s.Type) {
+		return []Violation{violation(pointer, "type",
+			fmt.Sprintf("expected %s, got %s", strings.Join(s.Type, " or "), typeOf(value)))}
+	}
+
+	var violations []Violation
+
+	if len(s.Enum) > 0 && !containsValue(s.Enum, value) {
+		violations = append(violations, violation(pointer, "enum",
+			fmt.Sprintf("must be one of %s", formatValues(s.Enum))))
+	}
+
+	if s.Const != nil && !equalValues(s.Const, value) {
+		violations = append(violations, violation(pointer, "const",
+			fmt.Sprintf("must be %s", formatValues([]interface{}{s.Const}))))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		violations = append(violations, s.validateObject(v, pointer)...)
+	case []interface{}:
+		violations = append(violations, s.validateArray(v, pointer)...)
+	case string:
+		violations = append(violations, s.validateString(v, pointer)...)
+	default:
+		if number, ok := toFloat(value); ok {
+			violations = append(violations, s.validateNumber(number, pointer)...)
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		violations = append(violations, sub.validate(value, pointer)...)
+	}
+
+	if len(s.AnyOf) > 0 {
+		matched, best := matchBranches(s.AnyOf, value, pointer)
+		if matched == 0 {
+			violations = append(violations, violation(pointer, "anyOf", "must match at least one schema in anyOf"))
+			violations = append(violations, best...)
+		}
+	}
+
+	if len(s.OneOf) > 0 {
+		matched, best := matchBranches(s.OneOf, value, pointer)
+		switch {
+		case matched == 0:
+			violations = append(violations, violation(pointer, "oneOf", "must match exactly one schema in oneOf"))
+			violations = append(violations, best...)
+		case matched > 1:
+			violations = append(violations, violation(pointer, "oneOf",
+				fmt.Sprintf("must match exactly one schema in oneOf, matched %d", matched)))
+		}
+	}
+
+	return violations
+}
+
+// validateObject 校验对象关键字
+func (s *Schema) validateObject(object map[string]interface{}, pointer string) []Violation {
+	var violations []Violation
+
+	for _, name := range s.Required {
+		if _, exists := object[name]; !exists {
+			violations = append(violations, violation(childPointer(pointer, name), "required",
+				fmt.Sprintf("missing required property %q", name)))
+		}
+	}
+
+	// 按属性名排序，保证违规项顺序稳定
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if property, exists := s.Properties[name]; exists {
+			violations = append(violations, property.validate(object[name], childPointer(pointer, name))...)
+			continue
+		}
+
+		if s.AdditionalProperties != nil {
+			if s.AdditionalProperties.never {
+				violations = append(violations, violation(childPointer(pointer, name), "additionalProperties",
+					fmt.Sprintf("unexpected property %q", name)))
+				continue
+			}
+			violations = append(violations, s.AdditionalProperties.validate(object[name], childPointer(pointer, name))...)
+		}
+	}
+
+	return violations
+}
+
+// validateArray 校验数组关键字
+func (s *Schema) validateArray(array []interface{}, pointer string) []Violation {
+	var violations []Violation
+
+	if s.MinItems != nil && len(array) < *s.MinItems {
+		violations = append(violations, violation(pointer, "minItems",
+			fmt.Sprintf("must contain at least %d items", *s.MinItems)))
+	}
+	if s.MaxItems != nil && len(array) > *s.MaxItems {
+		violations = append(violations, violation(pointer, "maxItems",
+			fmt.Sprintf("must contain at most %d items", *s.MaxItems)))
+	}
+
+	if s.Items != nil {
+		for i, item := range array {
+			violations = append(violations, s.Items.validate(item, childPointer(pointer, strconv.Itoa(i)))...)
+		}
+	}
+
+	return violations
+}
+
+// validateString 校验字符串关键字
+func (s *Schema) validateString(str string, pointer string) []Violation {
+	var violations []Violation
+
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		violations = append(violations, violation(pointer, "minLength",
+			fmt.Sprintf("must be at least %d characters", *s.MinLength)))
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		violations = append(violations, violation(pointer, "maxLength",
+			fmt.Sprintf("must be at most %d characters", *s.MaxLength)))
+	}
+
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		if err != nil {
+			violations = append(violations, violation(pointer, "pattern",
+				fmt.Sprintf("invalid pattern %q in schema", s.Pattern)))
+		} else if !re.MatchString(str) {
+			violations = append(violations, violation(pointer, "pattern",
+				fmt.Sprintf("must match pattern %q", s.Pattern)))
+		}
+	}
+
+	return violations
+}
+
+// patterns 已编译的pattern，键为pattern字符串，值为*regexp.Regexp或编译错误
+var patterns sync.Map
+
+// compilePattern 编译pattern并缓存结果，每个pattern只编译一次
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := patterns.Load(pattern); ok {
+		if re, ok := cached.(*regexp.Regexp); ok {
+			return re, nil
+		}
+		return nil, cached.(error)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		patterns.Store(pattern, err)
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// validateNumber 校验数值关键字
+func (s *Schema) validateNumber(number float64, pointer string) []Violation {
+	var violations []Violation
+
+	if s.Minimum != nil && number < *s.Minimum {
+		violations = append(violations, violation(pointer, "minimum",
+			fmt.Sprintf("must be >= %v", *s.Minimum)))
+	}
+	if s.Maximum != nil && number > *s.Maximum {
+		violations = append(violations, violation(pointer, "maximum",
+			fmt.Sprintf("must be <= %v", *s.Maximum)))
+	}
+
+	return violations
+}
+
+// matchBranches 返回匹配的分支数，以及最接近匹配的分支的违规项。
+// 分支通常以单值enum/const属性（如action）区分，该属性不匹配说明选错了分支；
+// 所有分支都在同一区分属性上不匹配时，合并为一条列出全部可选值的违规项
+func matchBranches(branches []*Schema, value interface{}, pointer string) (int, []Violation) {
+	matched := 0
+	var best []Violation
+	bestScore := -1
+
+	discriminator := ""
+	var allowed []interface{}
+	allMismatch := true
+
+	for _, branch := range branches {
+		violations := branch.validate(value, pointer)
+		if len(violations) == 0 {
+			matched++
+			allMismatch = false
+			continue
+		}
+
+		score := 0
+		mismatch := ""
+		for _, v := range violations {
+			switch {
+			case isDiscriminatorMismatch(branch, v, pointer):
+				score += 1000
+				mismatch = v.Pointer
+			case v.Keyword == "enum" || v.Keyword == "const" || v.Keyword == "type":
+				score += 100
+			default:
+				score++
+			}
+		}
+
+		if mismatch == "" || (discriminator != "" && mismatch != discriminator) {
+			allMismatch = false
+		} else {
+			discriminator = mismatch
+			allowed = append(allowed, discriminatorValues(branch, mismatch, pointer)...)
+		}
+
+		if bestScore < 0 || score < bestScore {
+			best, bestScore = violations, score
+		}
+	}
+
+	if matched == 0 && allMismatch && discriminator != "" {
+		return 0, []Violation{violation(discriminator, "enum",
+			fmt.Sprintf("must be one of %s", formatValues(allowed)))}
+	}
+
+	return matched, best
+}
+
+// isDiscriminatorMismatch 判断违规项是否为分支区分属性（单值enum或const）不匹配
+func isDiscriminatorMismatch(branch *Schema, v Violation, pointer string) bool {
+	if v.Keyword != "enum" && v.Keyword != "const" {
+		return false
+	}
+	return len(discriminatorValues(branch, v.Pointer, pointer)) > 0
+}
+
+// discriminatorValues 返回分支区分属性允许的值，不是区分属性时返回nil
+func discriminatorValues(branch *Schema, violationPointer, pointer string) []interface{} {
+	name := strings.TrimPrefix(violationPointer, pointer+"/")
+	property, exists := branch.Properties[name]
+	if !exists || name == violationPointer {
+		return nil
+	}
+
+	switch {
+	case property.Const != nil:
+		return []interface{}{property.Const}
+	case len(property.Enum) == 1:
+		return property.Enum
+	default:
+		return nil
+	}
+}
+
+// matchesAnyType 判断值是否属于给定类型之一
+func matchesAnyType(value interface{}, types []string) bool {
+	for _, t := range types {
+		if matchesType(value, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesType 判断值是否属于给定类型
+func matchesType(value interface{}, t string) bool {
+	switch t {
+	case TypeNull:
+		return value == nil
+	case TypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case TypeObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case TypeArray:
+		_, ok := value.([]interface{})
+		return ok
+	case TypeString:
+		_, ok := value.(string)
+		return ok
+	case TypeNumber:
+		_, ok := toFloat(value)
+		return ok
+	case TypeInteger:
+		number, ok := toFloat(value)
+		return ok && number == math.Trunc(number)
+	default:
+		return false
+	}
+}
+
+// typeOf 返回值的JSON类型名称
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case map[string]interface{}:
+		return TypeObject
+	case []interface{}:
+		return TypeArray
+	case string:
+		return TypeString
+	}
+
+	if _, ok := toFloat(value); ok {
+		return TypeNumber
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// toFloat 将JSON数值转换为float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// containsValue 判断候选值中是否包含给定值
+func containsValue(candidates []interface{}, value interface{}) bool {
+	for _, candidate := range candidates {
+		if equalValues(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues 比较两个JSON值，数值按大小比较
+func equalValues(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// formatValues 将值列表格式化为JSON形式
+func formatValues(values []interface{}) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Sprint(values)
+	}
+	return string(data)
+}
+
+// childPointer 构造子元素的JSON Pointer（RFC 6901）
+func childPointer(pointer, token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+	return pointer + "/" + token
+}
+
+// violation 创建违规项，根位置的指针为空字符串
+func violation(pointer, keyword, message string) Violation {
+	return Violation{
+		Pointer: pointer,
+		Keyword: keyword,
+		Message: message,
+	}
+}
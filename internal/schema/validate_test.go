@@ -0,0 +1,121 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+// mustDecode 解码测试用的JSON值
+func mustDecode(t *testing.T, data string) interface{} {
+	t.Helper()
+	value, err := Decode([]byte(data))
+	if err != nil {
+		t.Fatalf("Decode(%s): %v", data, err)
+	}
+	return value
+}
+
+// mustParse 解析测试用的schema
+func mustParse(t *testing.T, data string) *Schema {
+	t.Helper()
+	s, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", data, err)
+	}
+	return s
+}
+
+func TestValidate(t *testing.T) {
+	const object = `{
+		"type": "object",
+		"properties": {
+			"query": {"type": "string", "minLength": 1, "maxLength": 5, "pattern": "^[a-z]+$"},
+			"limit": {"type": "integer", "minimum": 1, "maximum": 100},
+			"tags": {"type": "array", "items": {"type": "string", "enum": ["a", "b"]}, "maxItems": 2},
+			"a/b": {"const": 1}
+		},
+		"required": ["query"],
+		"additionalProperties": false
+	}`
+
+	const action = `{
+		"type": "object",
+		"oneOf": [
+			{"properties": {"action": {"enum": ["get"]}, "id": {"type": "integer"}}, "required": ["action", "id"]},
+			{"properties": {"action": {"const": "list"}, "limit": {"type": "integer"}}, "required": ["action"]}
+		]
+	}`
+
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   []string // pointer:keyword
+	}{
+		{name: "valid", schema: object, value: `{"query": "abc", "limit": 10, "tags": ["a"]}`},
+		{name: "type mismatch stops", schema: object, value: `"abc"`, want: []string{":type"}},
+		{name: "required", schema: object, value: `{}`, want: []string{"/query:required"}},
+		{name: "string bounds", schema: object, value: `{"query": ""}`, want: []string{"/query:minLength", "/query:pattern"}},
+		{name: "max length counts runes", schema: object, value: `{"query": "ééééé"}`, want: []string{"/query:pattern"}},
+		{name: "pattern", schema: object, value: `{"query": "ABC"}`, want: []string{"/query:pattern"}},
+		{name: "integer", schema: object, value: `{"query": "a", "limit": 1.5}`, want: []string{"/limit:type"}},
+		{name: "integer written as float", schema: object, value: `{"query": "a", "limit": 2.0}`},
+		{name: "number bounds", schema: object, value: `{"query": "a", "limit": 101}`, want: []string{"/limit:maximum"}},
+		{name: "array items", schema: object, value: `{"query": "a", "tags": ["a", "c", "b"]}`, want: []string{"/tags:maxItems", "/tags/1:enum"}},
+		{name: "additional properties", schema: object, value: `{"query": "a", "extra": 1}`, want: []string{"/extra:additionalProperties"}},
+		{name: "escaped pointer", schema: object, value: `{"query": "a", "a/b": 2}`, want: []string{"/a~1b:const"}},
+		{name: "type list", schema: `{"type": ["string", "null"]}`, value: `null`},
+		{name: "boolean schema", schema: `{"properties": {"x": false}}`, value: `{"x": 1}`, want: []string{"/x:false"}},
+		{name: "oneOf match", schema: action, value: `{"action": "get", "id": 1}`},
+		{name: "oneOf branch violation", schema: action, value: `{"action": "get"}`, want: []string{":oneOf", "/id:required"}},
+		{name: "oneOf unknown discriminator", schema: action, value: `{"action": "delete"}`, want: []string{":oneOf", "/action:enum"}},
+		{name: "anyOf", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, value: `true`, want: []string{":anyOf", ":type"}},
+		{name: "allOf", schema: `{"allOf": [{"minimum": 5}, {"maximum": 3}]}`, value: `4`, want: []string{":minimum", ":maximum"}},
+		{name: "invalid pattern", schema: `{"pattern": "("}`, value: `"x"`, want: []string{":pattern"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := mustParse(t, tt.schema).Validate(mustDecode(t, tt.value))
+
+			var got []string
+			for _, v := range violations {
+				got = append(got, v.Pointer+":"+v.Keyword)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("violations = %v, want %v (%+v)", got, tt.want, violations)
+			}
+		})
+	}
+}
+
+func TestValidateDiscriminatorMessage(t *testing.T) {
+	s := mustParse(t, `{"oneOf": [
+		{"properties": {"action": {"enum": ["get"]}}},
+		{"properties": {"action": {"const": "list"}}}
+	]}`)
+
+	violations := s.Validate(mustDecode(t, `{"action": "delete"}`))
+	last := violations[len(violations)-1]
+	if last.Message != `must be one of ["get","list"]` {
+		t.Fatalf("message = %q, want all discriminator values", last.Message)
+	}
+}
+
+func TestCompilePatternCachesResult(t *testing.T) {
+	first, err := compilePattern("^cached-[0-9]+$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := compilePattern("^cached-[0-9]+$")
+	if first != second {
+		t.Fatalf("compilePattern compiled the same pattern twice")
+	}
+
+	if _, err := compilePattern("(cached"); err == nil {
+		t.Fatalf("invalid pattern compiled")
+	}
+	if _, err := compilePattern("(cached"); err == nil {
+		t.Fatalf("cached invalid pattern returned no error")
+	}
+}
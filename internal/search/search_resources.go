@@ -0,0 +1,48 @@
+package search
+
+import (
+	"context"
+
+	"github.com/droid/go-mcp/internal/resources"
+)
+
+// ListResources 实现resources.Provider接口，以条目URL作为资源URI发布知识库
+func (t *SearchTool) ListResources(ctx context.Context) ([]resources.Resource, error) {
+	items := make([]resources.Resource, 0, len(t.knowledgeBase))
+	for _, item := range t.knowledgeBase {
+		if item.URL == "" {
+			continue
+		}
+
+		items = append(items, resources.Resource{
+			URI:         item.URL,
+			Name:        item.Title,
+			Description: "知识库来源: " + item.Source,
+			MimeType:    "text/plain",
+		})
+	}
+
+	return items, nil
+}
+
+// ListResourceTemplates 实现resources.Provider接口，知识库没有资源模板
+func (t *SearchTool) ListResourceTemplates(ctx context.Context) ([]resources.ResourceTemplate, error) {
+	return []resources.ResourceTemplate{}, nil
+}
+
+// ReadResource 实现resources.Provider接口
+func (t *SearchTool) ReadResource(ctx context.Context, uri string) ([]resources.Contents, error) {
+	for _, item := range t.knowledgeBase {
+		if item.URL != "" && item.URL == uri {
+			return []resources.Contents{
+				{
+					URI:      uri,
+					MimeType: "text/plain",
+					Text:     item.Content,
+				},
+			}, nil
+		}
+	}
+
+	return nil, resources.ErrNotFound
+}
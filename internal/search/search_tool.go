@@ -2,9 +2,12 @@ package search
 
 import (
 	"encoding/json"
-	"errors"
 	"sort"
 	"strings"
+
+	"github.com/droid/go-mcp/internal/resources"
+	"github.com/droid/go-mcp/internal/schema"
+	"github.com/droid/go-mcp/internal/tools"
 )
 
 // SearchResult 表示一个搜索结果
@@ -18,13 +21,25 @@ type SearchResult struct {
 
 // SearchParams 搜索参数
 type SearchParams struct {
-	Query        string   `json:"query"`
-	MaxResults   int      `json:"max_results,omitempty"`
-	Sources      []string `json:"sources,omitempty"`
-	FilterTerms  []string `json:"filter_terms,omitempty"`
-	ExcludeTerms []string `json:"exclude_terms,omitempty"`
+	Query        string   `json:"query" description:"搜索查询"`
+	MaxResults   int      `json:"max_results,omitempty" description:"最大结果数" default:"3" minimum:"1"`
+	Sources      []string `json:"sources,omitempty" description:"限制搜索的来源"`
+	FilterTerms  []string `json:"filter_terms,omitempty" description:"必须包含的术语"`
+	ExcludeTerms []string `json:"exclude_terms,omitempty" description:"必须排除的术语"`
+}
+
+// SearchResults 搜索工具的结构化结果
+type SearchResults struct {
+	Results []SearchResult `json:"results" description:"按相关性排序的搜索结果"`
 }
 
+var (
+	// searchSchema 由SearchParams生成的参数schema
+	searchSchema = schema.MustReflect(SearchParams{}).String()
+	// searchOutputSchema 由SearchResults生成的输出schema
+	searchOutputSchema = schema.MustReflect(SearchResults{}).String()
+)
+
 // SearchTool 实现搜索功能
 type SearchTool struct {
 	// 模拟数据库
@@ -84,53 +99,23 @@ func (t *SearchTool) Description() string {
 
 // ParameterSchema 实现Tool接口
 func (t *SearchTool) ParameterSchema() string {
-	return `{
-		"type": "object",
-		"properties": {
-			"query": {
-				"type": "string",
-				"description": "搜索查询"
-			},
-			"max_results": {
-				"type": "integer",
-				"description": "最大结果数",
-				"default": 3
-			},
-			"sources": {
-				"type": "array",
-				"items": {
-					"type": "string"
-				},
-				"description": "限制搜索的来源"
-			},
-			"filter_terms": {
-				"type": "array",
-				"items": {
-					"type": "string"
-				},
-				"description": "必须包含的术语"
-			},
-			"exclude_terms": {
-				"type": "array",
-				"items": {
-					"type": "string"
-				},
-				"description": "必须排除的术语"
-			}
-		},
-		"required": ["query"]
-	}`
+	return searchSchema
+}
+
+// OutputSchema 实现OutputSchemaTool接口
+func (t *SearchTool) OutputSchema() string {
+	return searchOutputSchema
 }
 
 // Execute 实现Tool接口
 func (t *SearchTool) Execute(paramsJSON json.RawMessage) (interface{}, error) {
 	var params SearchParams
 	if err := json.Unmarshal(paramsJSON, &params); err != nil {
-		return nil, errors.New("无效的搜索参数: " + err.Error())
+		return nil, tools.Errorf(tools.CodeInvalidParams, "无效的搜索参数: %v", err)
 	}
 
 	if params.Query == "" {
-		return nil, errors.New("搜索查询不能为空")
+		return nil, tools.NewError(tools.CodeInvalidParams, "搜索查询不能为空", nil)
 	}
 
 	maxResults := 3
@@ -146,7 +131,25 @@ func (t *SearchTool) Execute(paramsJSON json.RawMessage) (interface{}, error) {
 		results = results[:maxResults]
 	}
 
-	return results, nil
+	// 有URL的结果附带资源链接，客户端可通过resources/read读取全文
+	links := make([]tools.Content, 0, len(results))
+	for _, item := range results {
+		if item.URL != "" {
+			links = append(links, tools.ResourceLink(resources.Resource{
+				URI:      item.URL,
+				Name:     item.Title,
+				MimeType: "text/plain",
+			}))
+		}
+	}
+
+	result, err := tools.StructuredResult(SearchResults{Results: results}, links...)
+	if err != nil {
+		return nil, err
+	}
+	// REST和旧版消息仍返回结果数组
+	result.Plain = results
+	return result, nil
 }
 
 // search 执行搜索并对结果评分
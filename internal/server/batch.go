@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/droid/go-mcp/internal/tools"
+	"github.com/google/uuid"
+)
+
+// DefaultBatchParallelism 批量请求默认同时执行的条目数
+const DefaultBatchParallelism = 4
+
+// maxBatchSize 单个批量请求的最大条目数
+const maxBatchSize = 100
+
+// WithBatchParallelism 设置批量请求同时执行的条目数上限
+func WithBatchParallelism(n int) Option {
+	return func(s *MCPServer) {
+		s.batchParallelism = n
+	}
+}
+
+// runBatch 以最多parallelism个并发执行n个条目，全部结束后返回
+func runBatch(n, parallelism int, run func(i int)) {
+	if parallelism <= 0 || parallelism > n {
+		parallelism = n
+	}
+
+	slots := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			run(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// handleToolBatch 处理REST批量工具请求，响应按请求顺序排列，每条带有各自的状态。
+// 查询参数parallelism可以降低本次请求的并发数
+func (s *MCPServer) handleToolBatch(w http.ResponseWriter, r *http.Request, requests []ToolRequest) {
+	if len(requests) == 0 {
+		http.Error(w, "Empty batch", http.StatusBadRequest)
+		return
+	}
+	if len(requests) > maxBatchSize {
+		http.Error(w, "Batch too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	parallelism := s.batchParallelism
+	if requested, err := strconv.Atoi(r.URL.Query().Get("parallelism")); err == nil && requested > 0 && requested < parallelism {
+		parallelism = requested
+	}
+
+	caller := tools.Caller{
+		ID:        r.RemoteAddr,
+		Transport: "rest",
+	}
+	ctx := tools.WithCaller(r.Context(), caller)
+
+	// 本次批量中的异步任务共用一个所有者标识
+	owner := uuid.New().String()
+
+	responses := make([]ToolResponse, len(requests))
+	runBatch(len(requests), parallelism, func(i int) {
+		request := requests[i]
+		if request.ID == "" {
+			request.ID = uuid.New().String()
+		}
+
+		if request.Async {
+			job := s.startJob(caller, owner, request, nil, nil)
+			responses[i] = ToolResponse{
+				RequestID: request.ID,
+				Status:    "accepted",
+				Result:    job,
+			}
+			return
+		}
+
+		responses[i] = s.executeToolRequest(ctx, request)
+	})
+
+	writeJSON(w, http.StatusOK, responses)
+}
+
+// handleRPCBatch 处理WebSocket和stdio上的JSON-RPC批量请求，响应数组写入客户端发送队列；
+// 批量中只有通知或请求均被取消时不返回响应
+func (s *MCPServer) handleRPCBatch(ctx context.Context, c *Client, data []byte) {
+	messages, rpcErr := parseBatch(data)
+	if rpcErr != nil {
+		log.Printf("Invalid JSON-RPC batch from %s: %s", c.ID, rpcErr.Message)
+		c.send(jsonrpc.NewErrorResponse(nil, rpcErr))
+		return
+	}
+
+	s.dispatchBatch(ctx, c, messages, c.send, func(responses []jsonrpc.Response) {
+		if len(responses) > 0 {
+			c.send(responses)
+		}
+	})
+}
+
+// parseBatch 解析批量请求并检查条目数
+func parseBatch(data []byte) ([]json.RawMessage, *jsonrpc.Error) {
+	messages, rpcErr := jsonrpc.ParseBatch(data)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if len(messages) > maxBatchSize {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidRequest, "Batch too large", map[string]interface{}{"max": maxBatchSize})
+	}
+	return messages, nil
+}
+
+// dispatchBatch 执行批量中的消息：通知立即处理，请求以批量并发数执行，中间通知交给notify，
+// 全部结束后以按请求顺序排列的响应调用done，被取消的请求没有响应。
+// 批量中没有请求时返回false，此时不调用done
+func (s *MCPServer) dispatchBatch(ctx context.Context, c *Client, messages []json.RawMessage,
+	notify func(interface{}), done func([]jsonrpc.Response)) bool {
+	// 批量中的请求共用一个响应信号，响应数组发送后才推送异步任务完成通知
+	ctx, replied := withReplyGate(ctx)
+
+	// 整个批量登记为执行中，stdio在输入结束后等待响应数组发送完毕再退出
+	untrack := c.inflight.track()
+
+	type item struct {
+		request  jsonrpc.Request
+		ctx      context.Context
+		finish   func() bool
+		stop     func()
+		response *jsonrpc.Response
+	}
+
+	// 在读取后续消息之前登记所有工具调用，使随后到达的取消通知能够生效
+	items := make([]*item, 0, len(messages))
+	for _, message := range messages {
+		request, rpcErr := jsonrpc.ParseRequest(message)
+		if rpcErr != nil {
+			response := jsonrpc.NewErrorResponse(request.ID, rpcErr)
+			items = append(items, &item{response: &response})
+			continue
+		}
+
+		if request.IsNotification() {
+			s.handleNotification(c, request)
+			continue
+		}
+
+		it := &item{request: request, ctx: ctx}
+		if request.Method == MethodToolsCall {
+			callCtx, finish, ok := c.inflight.begin(ctx, request.ID)
+			if !ok {
+				response := jsonrpc.NewErrorResponse(request.ID,
+					jsonrpc.NewError(jsonrpc.CodeInvalidRequest, "Duplicate request id", nil))
+				it.response = &response
+			} else {
+				it.ctx, it.stop = withProgress(callCtx, request, notify)
+				it.finish = finish
+			}
+		}
+		items = append(items, it)
+	}
+
+	if len(items) == 0 {
+		replied()
+		untrack()
+		return false
+	}
+
+	go func() {
+		runBatch(len(items), s.batchParallelism, func(i int) {
+			it := items[i]
+			if it.response != nil {
+				return
+			}
+
+			response := s.respond(it.ctx, c, it.request)
+			if it.stop != nil {
+				it.stop()
+			}
+			if it.finish != nil && it.finish() {
+				log.Printf("Request %s from %s cancelled", requestKey(it.request.ID), c.ID)
+				return
+			}
+			it.response = &response
+		})
+
+		responses := make([]jsonrpc.Response, 0, len(items))
+		for _, it := range items {
+			if it.response != nil {
+				responses = append(responses, *it.response)
+			}
+		}
+		done(responses)
+		replied()
+		untrack()
+	}()
+	return true
+}
+
+// decodeToolRequests 解析REST工具请求体，返回请求列表以及是否为批量请求
+func decodeToolRequests(body []byte) ([]ToolRequest, bool, error) {
+	if jsonrpc.IsBatch(body) {
+		var requests []ToolRequest
+		err := json.Unmarshal(body, &requests)
+		return requests, true, err
+	}
+
+	var request ToolRequest
+	err := json.Unmarshal(body, &request)
+	return []ToolRequest{request}, false, err
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+func TestRunBatch(t *testing.T) {
+	tests := []struct {
+		n, parallelism int
+		wantMax        int
+	}{
+		{n: 5, parallelism: 2, wantMax: 2},
+		{n: 3, parallelism: 8, wantMax: 3},
+		{n: 4, parallelism: 0, wantMax: 4},
+		{n: 6, parallelism: 1, wantMax: 1},
+		{n: 0, parallelism: 4, wantMax: 0},
+	}
+
+	for _, tt := range tests {
+		var running, peak int32
+		var mutex sync.Mutex
+		ran := make([]bool, tt.n)
+
+		runBatch(tt.n, tt.parallelism, func(i int) {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+
+			mutex.Lock()
+			if current > peak {
+				peak = current
+			}
+			ran[i] = true
+			mutex.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+		})
+
+		for i, ok := range ran {
+			if !ok {
+				t.Fatalf("runBatch(%d, %d) skipped item %d", tt.n, tt.parallelism, i)
+			}
+		}
+		if int(peak) != tt.wantMax {
+			t.Fatalf("runBatch(%d, %d) ran %d items at once, want %d", tt.n, tt.parallelism, peak, tt.wantMax)
+		}
+	}
+}
+
+// sleepParams 测试工具的参数，工具等待Ms毫秒后返回Name
+type sleepParams struct {
+	Name string `json:"name"`
+	Ms   int    `json:"ms,omitempty"`
+}
+
+// newBatchClient 创建注册了sleep工具的服务器和已完成初始化的stdio客户端
+func newBatchClient(t *testing.T) (*MCPServer, *Client) {
+	t.Helper()
+
+	toolMgr := tools.NewToolManager()
+	toolMgr.RegisterContextTool(tools.NewTypedTool("sleep", "Sleep then return the name",
+		func(ctx context.Context, params sleepParams) (string, error) {
+			select {
+			case <-time.After(time.Duration(params.Ms) * time.Millisecond):
+				return params.Name, nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}))
+
+	s := NewMCPServer(toolMgr, WithBatchParallelism(4))
+	// Run接收工具结果的广播
+	go s.Run()
+	c := newClient(s, "test", ProtocolJSONRPC, TransportStdio, nil)
+	t.Cleanup(c.close)
+
+	if _, rpcErr := s.initialize(c, InitializeParams{ProtocolVersion: LatestProtocolVersion}); rpcErr != nil {
+		t.Fatalf("initialize: %v", rpcErr.Message)
+	}
+	s.handleNotification(c, jsonrpc.Request{JSONRPC: jsonrpc.Version, Method: NotificationInitialized})
+	return s, c
+}
+
+// dispatch 执行批量请求并等待响应数组
+func dispatch(t *testing.T, s *MCPServer, c *Client, batch string) ([]jsonrpc.Response, bool) {
+	t.Helper()
+
+	messages, rpcErr := parseBatch([]byte(batch))
+	if rpcErr != nil {
+		t.Fatalf("parseBatch: %s", rpcErr.Message)
+	}
+
+	result := make(chan []jsonrpc.Response, 1)
+	if !s.dispatchBatch(context.Background(), c, messages, c.send, func(responses []jsonrpc.Response) {
+		result <- responses
+	}) {
+		return nil, false
+	}
+
+	select {
+	case responses := <-result:
+		return responses, true
+	case <-time.After(5 * time.Second):
+		t.Fatalf("batch did not finish")
+		return nil, false
+	}
+}
+
+// responseIDs 返回响应的id，没有id的响应为null
+func responseIDs(responses []jsonrpc.Response) []string {
+	ids := make([]string, len(responses))
+	for i, response := range responses {
+		ids[i] = string(response.ID)
+		if ids[i] == "" {
+			ids[i] = "null"
+		}
+	}
+	return ids
+}
+
+func TestDispatchBatchOrder(t *testing.T) {
+	s, c := newBatchClient(t)
+
+	// 先发送的调用执行得最慢，响应仍按请求顺序排列
+	responses, ok := dispatch(t, s, c, `[
+		{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "sleep", "arguments": {"name": "first", "ms": 80}}},
+		{"jsonrpc": "2.0", "method": "notifications/progress"},
+		{"jsonrpc": "1.0", "id": 2, "method": "ping"},
+		{"jsonrpc": "2.0", "id": "b", "method": "tools/call", "params": {"name": "sleep", "arguments": {"name": "second", "ms": 40}}},
+		{"jsonrpc": "2.0", "id": 3, "method": "ping"},
+		{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": {"name": "sleep", "arguments": {"name": "third"}}},
+		{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "sleep", "arguments": {"name": "dup"}}},
+		{"jsonrpc": "2.0", "id": 5, "method": "unknown/method"},
+		"not a request"
+	]`)
+	if !ok {
+		t.Fatalf("dispatchBatch returned false for a batch with requests")
+	}
+
+	want := []string{"1", "2", `"b"`, "3", "4", "1", "5", "null"}
+	if got := responseIDs(responses); !reflect.DeepEqual(got, want) {
+		t.Fatalf("response ids = %v, want %v", got, want)
+	}
+
+	texts := map[int]string{0: "first", 2: "second", 4: "third"}
+	for i, text := range texts {
+		result, ok := responses[i].Result.(CallToolResult)
+		if !ok || len(result.Content) != 1 || result.Content[0].Text != text {
+			t.Fatalf("response %d = %+v, want text %q", i, responses[i].Result, text)
+		}
+	}
+
+	codes := map[int]int{
+		1: jsonrpc.CodeInvalidRequest,
+		5: jsonrpc.CodeInvalidRequest,
+		6: jsonrpc.CodeMethodNotFound,
+		7: jsonrpc.CodeInvalidRequest,
+	}
+	for i, code := range codes {
+		if responses[i].Error == nil || responses[i].Error.Code != code {
+			t.Fatalf("response %d error = %+v, want code %d", i, responses[i].Error, code)
+		}
+	}
+
+	if c.inflight.count() != 0 {
+		t.Fatalf("%d calls still in flight after the batch", c.inflight.count())
+	}
+}
+
+func TestDispatchBatchCancelled(t *testing.T) {
+	s, c := newBatchClient(t)
+
+	// 批量中随后的取消通知在请求执行前生效，被取消的请求没有响应
+	responses, ok := dispatch(t, s, c, `[
+		{"jsonrpc": "2.0", "id": "slow", "method": "tools/call", "params": {"name": "sleep", "arguments": {"name": "slow", "ms": 5000}}},
+		{"jsonrpc": "2.0", "id": "fast", "method": "tools/call", "params": {"name": "sleep", "arguments": {"name": "fast"}}},
+		{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": "slow"}}
+	]`)
+	if !ok {
+		t.Fatalf("dispatchBatch returned false for a batch with requests")
+	}
+
+	if got := responseIDs(responses); !reflect.DeepEqual(got, []string{`"fast"`}) {
+		t.Fatalf("response ids = %v, want only the uncancelled request", got)
+	}
+}
+
+func TestDispatchBatchNotificationsOnly(t *testing.T) {
+	s, c := newBatchClient(t)
+
+	_, ok := dispatch(t, s, c, `[
+		{"jsonrpc": "2.0", "method": "notifications/progress"},
+		{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": 9}}
+	]`)
+	if ok {
+		t.Fatalf("dispatchBatch returned true for a notification-only batch")
+	}
+
+	// 批量结束后不再登记为执行中，stdio可以立即退出
+	waited := make(chan struct{})
+	go func() {
+		c.inflight.wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatalf("notification-only batch is still tracked as in flight")
+	}
+}
+
+func TestParseBatchLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		code int
+	}{
+		{name: "empty", data: `[]`, code: jsonrpc.CodeInvalidRequest},
+		{name: "malformed", data: `[{"jsonrpc": "2.0"`, code: jsonrpc.CodeParseError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, rpcErr := parseBatch([]byte(tt.data))
+			if rpcErr == nil || rpcErr.Code != tt.code {
+				t.Fatalf("parseBatch error = %+v, want code %d", rpcErr, tt.code)
+			}
+		})
+	}
+
+	items := make([]json.RawMessage, maxBatchSize+1)
+	for i := range items {
+		items[i] = json.RawMessage(`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`)
+	}
+	data, _ := json.Marshal(items)
+	if _, rpcErr := parseBatch(data); rpcErr == nil || rpcErr.Message != "Batch too large" {
+		t.Fatalf("parseBatch of %d items = %+v, want Batch too large", len(items), rpcErr)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// inflightCall 一个正在执行的请求
+type inflightCall struct {
+	cancel    context.CancelFunc
+	cancelled bool // 客户端通过notifications/cancelled取消了该请求
+}
+
+// inflightCalls 按请求ID记录客户端正在执行的请求
+type inflightCalls struct {
+	mutex sync.Mutex
+	calls map[string]*inflightCall
+	wg    sync.WaitGroup
+}
+
+// requestKey 将JSON-RPC请求ID规范化为map键
+func requestKey(id json.RawMessage) string {
+	return string(bytes.TrimSpace(id))
+}
+
+// begin 登记一个请求，返回可取消的context和结束函数；
+// 结束函数返回该请求是否已被客户端取消。ID重复时返回false
+func (ic *inflightCalls) begin(ctx context.Context, id json.RawMessage) (context.Context, func() bool, bool) {
+	ic.mutex.Lock()
+	defer ic.mutex.Unlock()
+
+	key := requestKey(id)
+	if _, exists := ic.calls[key]; exists {
+		return nil, nil, false
+	}
+
+	if ic.calls == nil {
+		ic.calls = make(map[string]*inflightCall)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	call := &inflightCall{cancel: cancel}
+	ic.calls[key] = call
+	ic.wg.Add(1)
+
+	finish := func() bool {
+		ic.mutex.Lock()
+		defer ic.mutex.Unlock()
+
+		delete(ic.calls, key)
+		cancel()
+		ic.wg.Done()
+		return call.cancelled
+	}
+
+	return ctx, finish, true
+}
+
+// cancel 取消指定ID的请求，请求不存在时返回false
+func (ic *inflightCalls) cancel(id json.RawMessage) bool {
+	ic.mutex.Lock()
+	defer ic.mutex.Unlock()
+
+	call, exists := ic.calls[requestKey(id)]
+	if !exists {
+		return false
+	}
+
+	call.cancelled = true
+	call.cancel()
+	return true
+}
+
+// track 登记一项不按请求ID取消的工作（如批量请求），使wait等待其结束，返回结束函数
+func (ic *inflightCalls) track() func() {
+	ic.wg.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(ic.wg.Done)
+	}
+}
+
+// count 返回正在执行的请求数
+func (ic *inflightCalls) count() int {
+	ic.mutex.Lock()
+	defer ic.mutex.Unlock()
+	return len(ic.calls)
+}
+
+// wait 等待所有正在执行的请求结束
+func (ic *inflightCalls) wait() {
+	ic.wg.Wait()
+}
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/droid/go-mcp/internal/jobs"
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/droid/go-mcp/internal/tools"
+	"github.com/google/uuid"
+)
+
+// 异步任务相关方法名
+const (
+	MethodJobsList   = "jobs/list"
+	MethodJobsGet    = "jobs/get"
+	MethodJobsCancel = "jobs/cancel"
+
+	// NotificationJobCompleted 任务结束时发送给发起任务的客户端
+	NotificationJobCompleted = "notifications/jobs/completed"
+)
+
+// MetaAsync tools/call请求_meta中的异步标志，为true时工具以异步任务执行
+const MetaAsync = "async"
+
+// JobOwnerHeader REST任务接口中携带任务所有者标识的请求头和响应头。
+// 所有者标识不放在URL中，避免出现在访问日志和Referer里
+const JobOwnerHeader = "X-Job-Owner"
+
+// JobParams jobs/get和jobs/cancel请求参数
+type JobParams struct {
+	JobID string `json:"jobId"`
+}
+
+// JobInfo JSON-RPC客户端看到的任务，结果与tools/call的结果格式相同
+type JobInfo struct {
+	jobs.Job
+	Result *CallToolResult `json:"result,omitempty"`
+	Error  *jsonrpc.Error  `json:"error,omitempty"`
+}
+
+// WithJobTTL 设置已结束的异步任务的保留时间
+func WithJobTTL(ttl time.Duration) Option {
+	return func(s *MCPServer) {
+		s.jobs = jobs.NewStore(ttl)
+	}
+}
+
+// startJob 以异步任务执行工具请求，owner为服务器生成的所有者标识，只有所有者可以查询和取消任务。
+// pool不为nil时任务在客户端的工作池中排队；任务不随发起请求的连接断开而取消，只能通过任务取消接口取消
+func (s *MCPServer) startJob(caller tools.Caller, owner string, request ToolRequest, pool *workerPool, onDone func(jobs.Job)) jobs.Job {
+	ctx := tools.WithCaller(context.Background(), caller)
+
+	job := s.jobs.Start(ctx, request.Tool, owner, func(ctx context.Context) tools.ToolResponse {
+		if pool != nil {
+			release, err := pool.acquire(ctx, request.Tool)
+			if err != nil {
+				return tools.ErrorResponse(err)
+			}
+			defer release()
+		}
+		return s.runTool(ctx, request)
+	}, onDone)
+
+	log.Printf("Job %s started: %s (%s)", job.ID, request.Tool, caller.ID)
+	return job
+}
+
+// startClientJob 为JSON-RPC客户端启动异步任务，任务结束时向该客户端发送
+// notifications/jobs/completed。通知在ctx对应请求的响应发送之后才发送
+func (s *MCPServer) startClientJob(ctx context.Context, c *Client, request ToolRequest) CallToolResult {
+	job := s.startJob(c.caller(), c.owner, request, c.pool, func(job jobs.Job) {
+		awaitReply(ctx)
+		log.Printf("Job %s %s", job.ID, job.Status)
+		c.send(jsonrpc.NewNotification(NotificationJobCompleted, s.jobInfo(job)))
+	})
+
+	return CallToolResult{
+		Content: []tools.Content{tools.TextContent(fmt.Sprintf("Job %s started", job.ID))},
+		Meta: map[string]interface{}{
+			"jobId":  job.ID,
+			"status": job.Status,
+		},
+	}
+}
+
+// jobInfo 将已成功或失败的任务结果转换为tools/call的结果格式
+func (s *MCPServer) jobInfo(job jobs.Job) JobInfo {
+	info := JobInfo{Job: job}
+
+	var response tools.ToolResponse
+	switch job.Status {
+	case jobs.StatusSucceeded:
+		response = tools.ToolResponse{Status: "success", Content: job.Result}
+	case jobs.StatusFailed:
+		response = tools.ToolResponse{Status: "error", Error: job.Error, Code: job.Code, Details: job.Details}
+	default:
+		return info
+	}
+
+	result, rpcErr := s.toolResult(job.Tool, response)
+	if rpcErr != nil {
+		info.Error = rpcErr
+		return info
+	}
+	if callResult, ok := result.(CallToolResult); ok {
+		info.Result = &callResult
+	}
+	return info
+}
+
+// handleJobRequest 处理异步任务相关请求，客户端只能访问自己发起的任务
+func (s *MCPServer) handleJobRequest(c *Client, request jsonrpc.Request) (interface{}, *jsonrpc.Error) {
+	if request.Method == MethodJobsList {
+		list := s.jobs.List(c.owner)
+		infos := make([]JobInfo, 0, len(list))
+		for _, job := range list {
+			infos = append(infos, s.jobInfo(job))
+		}
+		return map[string]interface{}{"jobs": infos}, nil
+	}
+
+	var params JobParams
+	if err := unmarshalParams(request.Params, &params); err != nil {
+		return nil, err
+	}
+	if params.JobID == "" {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Invalid params", "jobId is required")
+	}
+
+	job, exists := s.jobs.Get(params.JobID)
+	if !exists || job.Owner != c.owner {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Unknown job", params.JobID)
+	}
+
+	switch request.Method {
+	case MethodJobsGet:
+		return s.jobInfo(job), nil
+
+	case MethodJobsCancel:
+		job, _ = s.jobs.Cancel(params.JobID)
+		log.Printf("Job %s cancelled by %s", job.ID, c.ID)
+		return s.jobInfo(job), nil
+
+	default:
+		return nil, jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "Method not found", request.Method)
+	}
+}
+
+// handleLegacyJob 为旧版客户端启动异步任务：立即发送job_accepted，
+// 任务结束后发送job_result
+func (c *Client) handleLegacyJob(toolRequest ToolRequest) {
+	accepted := make(chan struct{})
+
+	job := c.Server.startJob(c.caller(), c.owner, toolRequest, c.pool, func(job jobs.Job) {
+		<-accepted
+		c.send(Message{
+			ID:      uuid.New().String(),
+			Type:    "job_result",
+			Content: plainJob(job),
+		})
+	})
+
+	c.send(Message{
+		ID:      uuid.New().String(),
+		Type:    "job_accepted",
+		Content: job,
+	})
+	close(accepted)
+}
+
+// acceptJob 为REST请求启动异步任务并返回202，新生成的所有者标识在返回的任务和
+// X-Job-Owner响应头中，查询和取消任务时需要在请求头中提供
+func (s *MCPServer) acceptJob(w http.ResponseWriter, r *http.Request, request ToolRequest) {
+	job := s.startJob(tools.Caller{
+		ID:        r.RemoteAddr,
+		Transport: "rest",
+	}, uuid.New().String(), request, nil, nil)
+
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	w.Header().Set(JobOwnerHeader, job.Owner)
+	writeJSON(w, http.StatusAccepted, ToolResponse{
+		RequestID: request.ID,
+		Status:    "accepted",
+		Result:    job,
+	})
+}
+
+// HandleJobs 处理任务接口：GET /jobs列出任务，GET /jobs/{id}查询任务，
+// DELETE /jobs/{id}取消任务。请求头X-Job-Owner为必填的所有者标识，只能访问该所有者的任务
+func (s *MCPServer) HandleJobs(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/jobs"), "/")
+
+	if r.Method != http.MethodGet && (id == "" || r.Method != http.MethodDelete) {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	owner := r.Header.Get(JobOwnerHeader)
+	if owner == "" {
+		http.Error(w, JobOwnerHeader+" header is required", http.StatusBadRequest)
+		return
+	}
+
+	if id == "" {
+		list := s.jobs.List(owner)
+		for i := range list {
+			list[i] = plainJob(list[i])
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"jobs": list,
+		})
+		return
+	}
+
+	job, exists := s.jobs.Get(id)
+	if !exists || job.Owner != owner {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		job, _ = s.jobs.Cancel(id)
+		log.Printf("Job %s cancelled by %s", id, r.RemoteAddr)
+	}
+	writeJSON(w, http.StatusOK, plainJob(job))
+}
+
+// plainJob 返回REST和旧版协议看到的任务，结果与同步调用的结果格式相同
+func plainJob(job jobs.Job) jobs.Job {
+	job.Result = tools.PlainValue(job.Result)
+	return job
+}
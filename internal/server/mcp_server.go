@@ -1,12 +1,19 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/droid/go-mcp/internal/jobs"
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/droid/go-mcp/internal/prompts"
+	"github.com/droid/go-mcp/internal/resources"
 	"github.com/droid/go-mcp/internal/tools"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -26,23 +33,181 @@ type ToolRequest struct {
 	Tool     string          `json:"tool"`
 	Params   json.RawMessage `json:"params"`
 	Metadata interface{}     `json:"metadata,omitempty"`
+	Async    bool            `json:"async,omitempty"` // 以异步任务执行，立即返回任务ID
 }
 
 // ToolResponse 工具响应
 type ToolResponse struct {
-	RequestID string      `json:"request_id"`
-	Status    string      `json:"status"`
-	Result    interface{} `json:"result,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	Metadata  interface{} `json:"metadata,omitempty"`
+	RequestID string                 `json:"request_id"`
+	Status    string                 `json:"status"`
+	Result    interface{}            `json:"result,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Code      string                 `json:"code,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Metadata  interface{}            `json:"metadata,omitempty"`
 }
 
+// 客户端使用的消息协议
+const (
+	// ProtocolJSONRPC 标准MCP JSON-RPC 2.0协议（默认）
+	ProtocolJSONRPC = "jsonrpc"
+	// ProtocolLegacy 旧版id/type/content消息封装
+	ProtocolLegacy = "legacy"
+)
+
+// 客户端使用的传输方式
+const (
+	TransportWebSocket = "websocket"
+	TransportStdio     = "stdio"
+	TransportHTTP      = "http"
+)
+
 // Client 客户端连接
 type Client struct {
 	ID         string
 	Connection *websocket.Conn
-	Send       chan Message
+	Send       chan interface{}
 	Server     *MCPServer
+	Protocol   string
+	Transport  string
+
+	// ctx 在客户端断开时取消，用于终止该客户端发起的工具调用
+	ctx     context.Context
+	cancel  context.CancelFunc
+	session session
+
+	// sendMutex 保护发送队列的关闭状态和SSE流状态
+	sendMutex sync.RWMutex
+	closed    bool
+
+	// streaming HTTP会话是否打开了SSE流，没有流时服务器消息无处投递
+	streaming bool
+	// lastActive HTTP会话最近一次收到请求或关闭SSE流的时间
+	lastActive time.Time
+
+	inflight      inflightCalls
+	pool          *workerPool
+	subscriptions subscriptions
+
+	// owner 客户端发起的异步任务的所有者标识，由服务器生成，
+	// 客户端可以自行指定的ID不用于任务鉴权
+	owner string
+}
+
+// newClient 创建新的客户端
+func newClient(s *MCPServer, id, protocol, transport string, conn *websocket.Conn) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Client{
+		ID:         id,
+		Connection: conn,
+		Send:       make(chan interface{}, 256),
+		Server:     s,
+		Protocol:   protocol,
+		Transport:  transport,
+		ctx:        ctx,
+		cancel:     cancel,
+		pool:       newWorkerPool(s.maxConcurrency, s.toolConcurrency),
+		lastActive: time.Now(),
+		owner:      uuid.New().String(),
+	}
+}
+
+// send 将消息放入发送队列，客户端已关闭时丢弃消息。
+// HTTP会话的消息只能由SSE流转发，因此不阻塞：没有打开的SSE流或队列已满时丢弃消息
+func (c *Client) send(message interface{}) {
+	if c.Transport == TransportHTTP {
+		c.trySend(message)
+		return
+	}
+
+	c.sendMutex.RLock()
+	defer c.sendMutex.RUnlock()
+
+	if c.closed {
+		return
+	}
+
+	select {
+	case c.Send <- message:
+	case <-c.ctx.Done():
+	}
+}
+
+// trySend 尝试将消息放入发送队列，队列已满、客户端已关闭或HTTP会话没有打开的SSE流时返回false
+func (c *Client) trySend(message interface{}) bool {
+	c.sendMutex.RLock()
+	defer c.sendMutex.RUnlock()
+
+	if c.closed || (c.Transport == TransportHTTP && !c.streaming) {
+		return false
+	}
+
+	select {
+	case c.Send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// attachStream 记录HTTP会话打开了SSE流，返回流关闭时调用的函数；
+// 会话已有打开的SSE流时返回false
+func (c *Client) attachStream() (func(), bool) {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+
+	if c.streaming {
+		return nil, false
+	}
+	c.streaming = true
+
+	return func() {
+		c.sendMutex.Lock()
+		c.streaming = false
+		c.lastActive = time.Now()
+		c.sendMutex.Unlock()
+	}, true
+}
+
+// touch 记录HTTP会话的活动时间
+func (c *Client) touch() {
+	c.sendMutex.Lock()
+	c.lastActive = time.Now()
+	c.sendMutex.Unlock()
+}
+
+// idle 判断HTTP会话是否已空闲超过ttl：没有打开的SSE流、没有执行中的请求，
+// 且最近一次活动早于ttl之前
+func (c *Client) idle(ttl time.Duration) bool {
+	c.sendMutex.RLock()
+	defer c.sendMutex.RUnlock()
+
+	return !c.streaming && c.inflight.count() == 0 && time.Since(c.lastActive) > ttl
+}
+
+// close 取消客户端的context并关闭发送队列
+func (c *Client) close() {
+	// 先取消context，唤醒阻塞在send中的goroutine
+	c.cancel()
+
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.Send)
+}
+
+// caller 返回客户端对应的工具调用方信息
+func (c *Client) caller() tools.Caller {
+	return tools.Caller{
+		ID:        c.ID,
+		Transport: c.Transport,
+		Client:    c.session.client().Name,
+	}
 }
 
 // MCPServer MCP服务器实现
@@ -52,9 +217,57 @@ type MCPServer struct {
 	unregister chan *Client
 	broadcast  chan Message
 	toolMgr    *tools.ToolManager
+	resMgr     *resources.Manager
+	prompts    *prompts.Registry
 	mutex      sync.RWMutex
+
+	// 每个客户端的工具调用并发上限
+	maxConcurrency  int
+	toolConcurrency map[string]int
+
+	// 批量请求同时执行的条目数
+	batchParallelism int
+
+	// 调试模式下按输出schema校验工具结果
+	debug bool
+
+	// 异步执行的工具调用
+	jobs *jobs.Store
+
+	// HTTP会话的空闲过期时间，0表示不过期
+	sessionTTL time.Duration
+
+	// 允许访问Streamable HTTP端点的Origin，为空时只允许本机Origin
+	allowedOrigins []string
+}
+
+// Option 配置MCP服务器
+type Option func(*MCPServer)
+
+// WithMaxConcurrency 设置每个客户端同时执行的工具调用上限
+func WithMaxConcurrency(n int) Option {
+	return func(s *MCPServer) {
+		s.maxConcurrency = n
+	}
+}
+
+// WithToolConcurrency 设置每个客户端同时执行指定工具的上限
+func WithToolConcurrency(tool string, n int) Option {
+	return func(s *MCPServer) {
+		s.toolConcurrency[tool] = n
+	}
+}
+
+// WithDebug 启用调试模式，工具结果不符合其输出schema时返回错误
+func WithDebug(enabled bool) Option {
+	return func(s *MCPServer) {
+		s.debug = enabled
+	}
 }
 
+// maxMessageSize 单条消息的最大字节数
+const maxMessageSize = 512 * 1024 // 512KB
+
 // WebSocket配置
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
@@ -62,23 +275,97 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true // 允许所有跨域请求
 	},
+	Subprotocols: []string{"mcp", "mcp-legacy"},
 }
 
 // NewMCPServer 创建新的MCP服务器
-func NewMCPServer(toolMgr *tools.ToolManager) *MCPServer {
-	return &MCPServer{
-		clients:    make(map[string]*Client),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		broadcast:  make(chan Message),
-		toolMgr:    toolMgr,
+func NewMCPServer(toolMgr *tools.ToolManager, opts ...Option) *MCPServer {
+	s := &MCPServer{
+		clients:          make(map[string]*Client),
+		register:         make(chan *Client),
+		unregister:       make(chan *Client),
+		broadcast:        make(chan Message),
+		toolMgr:          toolMgr,
+		maxConcurrency:   DefaultMaxConcurrency,
+		toolConcurrency:  make(map[string]int),
+		batchParallelism: DefaultBatchParallelism,
+		jobs:             jobs.NewStore(jobs.DefaultTTL),
+		sessionTTL:       DefaultSessionTTL,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.resMgr != nil {
+		s.resMgr.Watch(s.handleResourceChange)
+	}
+	toolMgr.OnChange(s.handleToolsChange)
+
+	return s
+}
+
+// handleToolsChange 工具列表变化时通知客户端：JSON-RPC客户端收到
+// notifications/tools/list_changed，旧版客户端收到新的工具清单
+func (s *MCPServer) handleToolsChange() {
+	s.mutex.RLock()
+	clients := make([]*Client, 0, len(s.clients))
+	for _, client := range s.clients {
+		clients = append(clients, client)
+	}
+	s.mutex.RUnlock()
+
+	var legacyTools interface{}
+	for _, client := range clients {
+		var message interface{}
+		switch {
+		case client.Protocol == ProtocolLegacy:
+			if legacyTools == nil {
+				legacyTools = s.toolMgr.GetToolsSchema()
+			}
+			message = Message{
+				ID:   uuid.New().String(),
+				Type: "tools",
+				Content: map[string]interface{}{
+					"tools": legacyTools,
+				},
+			}
+		case client.session.ready():
+			message = jsonrpc.NewNotification(NotificationToolsListChanged, nil)
+		default:
+			continue
+		}
+
+		if !client.trySend(message) {
+			log.Printf("Dropping tools change for %s: send queue full", client.ID)
+		}
+	}
+}
+
+// readyClients 返回完成握手的JSON-RPC客户端
+func (s *MCPServer) readyClients() []*Client {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	clients := make([]*Client, 0, len(s.clients))
+	for _, client := range s.clients {
+		if client.Protocol == ProtocolJSONRPC && client.session.ready() {
+			clients = append(clients, client)
+		}
 	}
+	return clients
 }
 
 // Run 启动MCP服务器的主循环
 func (s *MCPServer) Run() {
+	sweep := time.NewTicker(sessionSweepInterval(s.sessionTTL))
+	defer sweep.Stop()
+
 	for {
 		select {
+		case <-sweep.C:
+			s.expireSessions()
+
 		case client := <-s.register:
 			s.mutex.Lock()
 			s.clients[client.ID] = client
@@ -89,22 +376,37 @@ func (s *MCPServer) Run() {
 			s.mutex.Lock()
 			if _, ok := s.clients[client.ID]; ok {
 				delete(s.clients, client.ID)
-				close(client.Send)
+				client.close()
 				log.Printf("Client unregistered: %s", client.ID)
 			}
 			s.mutex.Unlock()
 
 		case message := <-s.broadcast:
+			// 发送队列已满的客户端被断开，持有写锁后再从客户端列表中删除
+			var stale []*Client
 			s.mutex.RLock()
 			for _, client := range s.clients {
-				select {
-				case client.Send <- message:
-				default:
-					close(client.Send)
-					delete(s.clients, client.ID)
+				// 广播只发给旧版客户端，JSON-RPC客户端只收到自己请求的响应
+				if client.Protocol != ProtocolLegacy {
+					continue
+				}
+
+				if !client.trySend(message) {
+					stale = append(stale, client)
 				}
 			}
 			s.mutex.RUnlock()
+
+			if len(stale) > 0 {
+				s.mutex.Lock()
+				for _, client := range stale {
+					if s.clients[client.ID] == client {
+						delete(s.clients, client.ID)
+					}
+					client.close()
+				}
+				s.mutex.Unlock()
+			}
 		}
 	}
 }
@@ -123,12 +425,7 @@ func (s *MCPServer) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		clientID = uuid.New().String()
 	}
 
-	client := &Client{
-		ID:         clientID,
-		Connection: conn,
-		Send:       make(chan Message, 256),
-		Server:     s,
-	}
+	client := newClient(s, clientID, negotiateProtocol(r, conn), TransportWebSocket, conn)
 
 	s.register <- client
 
@@ -136,8 +433,13 @@ func (s *MCPServer) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	go client.writePump()
 	go client.readPump()
 
+	// JSON-RPC客户端通过tools/list主动获取工具
+	if client.Protocol != ProtocolLegacy {
+		return
+	}
+
 	// 发送连接成功消息
-	client.Send <- Message{
+	client.send(Message{
 		ID:   uuid.New().String(),
 		Type: "connection",
 		Content: map[string]string{
@@ -148,16 +450,31 @@ func (s *MCPServer) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 			"timestamp": time.Now().Unix(),
 			"version":   "1.0",
 		},
-	}
+	})
 
 	// 发送可用工具清单
-	client.Send <- Message{
+	client.send(Message{
 		ID:   uuid.New().String(),
 		Type: "tools",
 		Content: map[string]interface{}{
 			"tools": s.toolMgr.GetToolsSchema(),
 		},
+	})
+}
+
+// negotiateProtocol 根据查询参数或WebSocket子协议确定客户端使用的消息协议
+func negotiateProtocol(r *http.Request, conn *websocket.Conn) string {
+	switch r.URL.Query().Get("protocol") {
+	case ProtocolLegacy:
+		return ProtocolLegacy
+	case ProtocolJSONRPC:
+		return ProtocolJSONRPC
+	}
+
+	if conn.Subprotocol() == "mcp-legacy" {
+		return ProtocolLegacy
 	}
+	return ProtocolJSONRPC
 }
 
 // HandleToolRequest 处理工具请求
@@ -167,19 +484,42 @@ func (s *MCPServer) HandleToolRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var request ToolRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	requests, batch, err := decodeToolRequests(body)
+	if err != nil {
 		http.Error(w, "Invalid request format", http.StatusBadRequest)
 		return
 	}
 
+	// 请求体为数组时批量执行
+	if batch {
+		s.handleToolBatch(w, r, requests)
+		return
+	}
+	request := requests[0]
+
 	// 确保请求ID存在
 	if request.ID == "" {
 		request.ID = uuid.New().String()
 	}
 
-	// 执行工具请求
-	response := s.executeToolRequest(request)
+	// 异步请求立即返回任务，结果通过/jobs查询
+	if request.Async {
+		s.acceptJob(w, r, request)
+		return
+	}
+
+	// 执行工具请求，客户端断开HTTP连接时取消
+	ctx := tools.WithCaller(r.Context(), tools.Caller{
+		ID:        r.RemoteAddr,
+		Transport: "rest",
+	})
+	response := s.executeToolRequest(ctx, request)
 
 	// 返回响应
 	w.Header().Set("Content-Type", "application/json")
@@ -187,8 +527,17 @@ func (s *MCPServer) HandleToolRequest(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// executeToolRequest 执行工具请求并返回响应
-func (s *MCPServer) executeToolRequest(request ToolRequest) ToolResponse {
+// executeToolRequest 执行工具请求并返回REST和旧版协议的响应，
+// 工具返回的*tools.Result被还原为结构化内容或文本
+func (s *MCPServer) executeToolRequest(ctx context.Context, request ToolRequest) ToolResponse {
+	result := s.runTool(ctx, request)
+	result.Content = tools.PlainValue(result.Content)
+	return newToolResponse(request.ID, request.Tool, result)
+}
+
+// runTool 执行工具请求并返回工具管理器的响应，结果保留工具返回的值，
+// 内容块由MCP层构建
+func (s *MCPServer) runTool(ctx context.Context, request ToolRequest) tools.ToolResponse {
 	log.Printf("Executing tool request: %s - %s", request.ID, request.Tool)
 
 	// 创建工具执行请求
@@ -196,23 +545,17 @@ func (s *MCPServer) executeToolRequest(request ToolRequest) ToolResponse {
 		Name:       request.Tool,
 		Parameters: request.Params,
 	}
+	if metadata, ok := request.Metadata.(map[string]interface{}); ok {
+		toolRequest.Metadata = metadata
+	}
 
 	// 执行工具
-	result := s.toolMgr.ExecuteTool(toolRequest)
-
-	// 构建响应
-	response := ToolResponse{
-		RequestID: request.ID,
-		Status:    result.Status,
-		Result:    result.Content,
-		Error:     result.Error,
-		Metadata: map[string]interface{}{
-			"timestamp": time.Now().Unix(),
-			"tool":      request.Tool,
-		},
+	result := s.toolMgr.ExecuteTool(ctx, toolRequest)
+	if s.debug && result.Status == "success" {
+		result = s.checkOutput(request.Tool, result)
 	}
 
-	// 广播工具执行结果（如果有需要）
+	// 向旧版客户端广播工具执行结果
 	if result.Status == "success" {
 		s.broadcast <- Message{
 			ID:   uuid.New().String(),
@@ -220,12 +563,46 @@ func (s *MCPServer) executeToolRequest(request ToolRequest) ToolResponse {
 			Content: map[string]interface{}{
 				"request_id": request.ID,
 				"tool":       request.Tool,
-				"result":     result.Content,
+				"result":     tools.PlainValue(result.Content),
 			},
 		}
 	}
 
-	return response
+	return result
+}
+
+// newToolResponse 由工具管理器的响应构建工具请求的响应
+func newToolResponse(requestID, tool string, result tools.ToolResponse) ToolResponse {
+	return ToolResponse{
+		RequestID: requestID,
+		Status:    result.Status,
+		Result:    result.Content,
+		Error:     result.Error,
+		Code:      result.Code,
+		Details:   result.Details,
+		Metadata: map[string]interface{}{
+			"timestamp": time.Now().Unix(),
+			"tool":      tool,
+		},
+	}
+}
+
+// checkOutput 按工具的输出schema校验结果，不符合时返回错误响应
+func (s *MCPServer) checkOutput(tool string, result tools.ToolResponse) tools.ToolResponse {
+	violations, err := s.toolMgr.ValidateOutput(tool, result.Content)
+	if err == nil && len(violations) == 0 {
+		return result
+	}
+
+	log.Printf("Tool %s returned a result that does not match its output schema: %v %v", tool, err, violations)
+
+	if err != nil {
+		return tools.ErrorResponse(tools.Errorf(tools.CodeInvalidOutput,
+			"Tool '%s' returned an invalid result: %v", tool, err))
+	}
+	return tools.ErrorResponse(tools.NewError(tools.CodeInvalidOutput,
+		fmt.Sprintf("Tool '%s' returned an invalid result: %d violation(s)", tool, len(violations)),
+		map[string]interface{}{"violations": violations}))
 }
 
 // GetAvailableTools 获取可用工具列表
@@ -237,6 +614,15 @@ func (s *MCPServer) GetAvailableTools(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// HandleMetrics 返回各工具的调用、失败、超时和panic统计
+func (s *MCPServer) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tools": s.toolMgr.Stats(),
+	})
+}
+
 // readPump 从WebSocket连接读取消息
 func (c *Client) readPump() {
 	defer func() {
@@ -244,7 +630,7 @@ func (c *Client) readPump() {
 		c.Connection.Close()
 	}()
 
-	c.Connection.SetReadLimit(512 * 1024) // 512KB
+	c.Connection.SetReadLimit(maxMessageSize)
 	c.Connection.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.Connection.SetPongHandler(func(string) error {
 		c.Connection.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -260,59 +646,106 @@ func (c *Client) readPump() {
 			break
 		}
 
-		// 尝试解析为工具请求
-		var toolRequest ToolRequest
-		if err := json.Unmarshal(message, &toolRequest); err == nil && toolRequest.Tool != "" {
-			response := c.Server.executeToolRequest(toolRequest)
-
-			// 将响应发送给客户端
-			c.Send <- Message{
-				ID:      uuid.New().String(),
-				Type:    "tool_response",
-				Content: response,
-			}
+		if c.Protocol == ProtocolLegacy {
+			c.handleLegacyMessage(message)
 			continue
 		}
 
-		// 否则尝试解析为一般消息
-		var msg Message
-		if err := json.Unmarshal(message, &msg); err != nil {
-			log.Printf("error decoding message: %v", err)
-			continue
-		}
+		c.Server.handleRPCMessage(c.ctx, c, message)
+	}
+}
 
-		// 如果消息没有ID，生成一个
-		if msg.ID == "" {
-			msg.ID = uuid.New().String()
+// handleLegacyMessage 处理旧版消息封装
+func (c *Client) handleLegacyMessage(message []byte) {
+	// 尝试解析为工具请求
+	var toolRequest ToolRequest
+	if err := json.Unmarshal(message, &toolRequest); err == nil && toolRequest.Tool != "" {
+		// 确保请求ID存在，客户端据此关联并发返回的响应
+		if toolRequest.ID == "" {
+			toolRequest.ID = uuid.New().String()
 		}
 
-		log.Printf("Received message from %s: %s", c.ID, string(message))
-
-		// 根据消息类型处理
-		switch msg.Type {
-		case "ping":
-			// 响应ping消息
-			c.Send <- Message{
-				ID:   uuid.New().String(),
-				Type: "pong",
-				Content: map[string]interface{}{
-					"timestamp": time.Now().Unix(),
-				},
-			}
-		case "get_tools":
-			// 发送可用工具列表
-			c.Send <- Message{
+		// 在启动goroutine之前占用排队名额，排队的调用已满时立即拒绝
+		admitted, ok := c.pool.admit()
+		if !ok {
+			c.send(Message{
 				ID:   uuid.New().String(),
-				Type: "tools",
-				Content: map[string]interface{}{
-					"tools": c.Server.toolMgr.GetToolsSchema(),
-				},
-			}
-		default:
-			// 默认广播消息
-			c.Server.broadcast <- msg
+				Type: "tool_response",
+				Content: newToolResponse(toolRequest.ID, toolRequest.Tool,
+					tools.ErrorResponse(c.pool.busyError())),
+			})
+			return
 		}
+
+		go func() {
+			defer admitted()
+			if toolRequest.Async {
+				c.handleLegacyJob(toolRequest)
+				return
+			}
+			c.handleLegacyToolRequest(toolRequest)
+		}()
+		return
+	}
+
+	// 否则尝试解析为一般消息
+	var msg Message
+	if err := json.Unmarshal(message, &msg); err != nil {
+		log.Printf("error decoding message: %v", err)
+		return
+	}
+
+	// 如果消息没有ID，生成一个
+	if msg.ID == "" {
+		msg.ID = uuid.New().String()
+	}
+
+	log.Printf("Received message from %s: %s", c.ID, string(message))
+
+	// 根据消息类型处理
+	switch msg.Type {
+	case "ping":
+		// 响应ping消息
+		c.send(Message{
+			ID:   uuid.New().String(),
+			Type: "pong",
+			Content: map[string]interface{}{
+				"timestamp": time.Now().Unix(),
+			},
+		})
+	case "get_tools":
+		// 发送可用工具列表
+		c.send(Message{
+			ID:   uuid.New().String(),
+			Type: "tools",
+			Content: map[string]interface{}{
+				"tools": c.Server.toolMgr.GetToolsSchema(),
+			},
+		})
+	default:
+		// 默认广播消息
+		c.Server.broadcast <- msg
+	}
+}
+
+// handleLegacyToolRequest 在工作池中执行旧版工具请求并发送响应
+func (c *Client) handleLegacyToolRequest(toolRequest ToolRequest) {
+	release, err := c.pool.acquire(c.ctx, toolRequest.Tool)
+	if err != nil {
+		// 客户端已断开
+		return
 	}
+
+	ctx := tools.WithCaller(c.ctx, c.caller())
+	response := c.Server.executeToolRequest(ctx, toolRequest)
+	release()
+
+	// 将响应发送给客户端
+	c.send(Message{
+		ID:      uuid.New().String(),
+		Type:    "tool_response",
+		Content: response,
+	})
 }
 
 // writePump 向WebSocket连接发送消息
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// DefaultMaxConcurrency 每个客户端默认允许同时执行的工具调用数
+const DefaultMaxConcurrency = 8
+
+// workerPool 限制单个客户端并发执行的工具调用数量，
+// 同时支持按工具单独设置上限
+type workerPool struct {
+	slots      chan struct{}
+	queue      chan struct{} // 已接收的调用（执行中和等待中），容量为总并发上限的两倍
+	toolLimits map[string]int
+	toolSlots  map[string]chan struct{}
+	mutex      sync.Mutex
+}
+
+// newWorkerPool 创建工作池，size为总并发上限，toolLimits为各工具的并发上限
+func newWorkerPool(size int, toolLimits map[string]int) *workerPool {
+	if size <= 0 {
+		size = DefaultMaxConcurrency
+	}
+
+	return &workerPool{
+		slots:      make(chan struct{}, size),
+		queue:      make(chan struct{}, 2*size),
+		toolLimits: toolLimits,
+		toolSlots:  make(map[string]chan struct{}),
+	}
+}
+
+// admit 在为调用启动goroutine之前占用一个排队名额，返回释放函数；
+// 执行中和等待中的调用已达上限时返回false，调用方应以busy错误拒绝请求
+func (p *workerPool) admit() (func(), bool) {
+	select {
+	case p.queue <- struct{}{}:
+		return func() { <-p.queue }, true
+	default:
+		return nil, false
+	}
+}
+
+// busyError 返回客户端排队的调用已满时的错误
+func (p *workerPool) busyError() *tools.Error {
+	return tools.NewError(tools.CodeBusy, "Too many pending tool calls, retry later",
+		map[string]interface{}{"max_pending": cap(p.queue)})
+}
+
+// acquire 等待指定工具的执行槽位，返回释放函数；ctx取消时返回错误
+func (p *workerPool) acquire(ctx context.Context, tool string) (func(), error) {
+	// 先占用工具槽位，避免在等待工具槽位时占用总槽位
+	toolSlot := p.toolSlot(tool)
+	if toolSlot != nil {
+		select {
+		case toolSlot <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		if toolSlot != nil {
+			<-toolSlot
+		}
+		return nil, ctx.Err()
+	}
+
+	release := func() {
+		<-p.slots
+		if toolSlot != nil {
+			<-toolSlot
+		}
+	}
+	return release, nil
+}
+
+// toolSlot 返回工具的槽位通道，未设置上限的工具返回nil
+func (p *workerPool) toolSlot(tool string) chan struct{} {
+	limit, exists := p.toolLimits[tool]
+	if !exists || limit <= 0 {
+		return nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	slot, exists := p.toolSlots[tool]
+	if !exists {
+		slot = make(chan struct{}, limit)
+		p.toolSlots[tool] = slot
+	}
+	return slot
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// NotificationProgress 进度通知方法名
+const NotificationProgress = "notifications/progress"
+
+// ProgressParams notifications/progress通知参数
+type ProgressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      float64         `json:"progress"`
+	Total         float64         `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
+
+// progressReporter 将工具进度转换为notifications/progress通知
+type progressReporter struct {
+	ctx   context.Context
+	token json.RawMessage
+	reply func(interface{})
+
+	mutex   sync.Mutex
+	last    float64
+	sent    bool
+	stopped bool
+}
+
+// Report 实现tools.ProgressReporter接口，进度必须递增，请求结束后的报告被丢弃
+func (p *progressReporter) Report(progress, total float64, message string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.stopped || p.ctx.Err() != nil || (p.sent && progress <= p.last) {
+		return
+	}
+	p.last = progress
+	p.sent = true
+
+	p.reply(jsonrpc.NewNotification(NotificationProgress, ProgressParams{
+		ProgressToken: p.token,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	}))
+}
+
+// stop 停止报告进度。正在发送的进度通知发送完毕后才返回，之后的报告被丢弃，
+// 因此在stop之后发送的响应不会被进度通知超过
+func (p *progressReporter) stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.stopped = true
+}
+
+// withProgress 如果请求携带了progressToken，在context中附加进度报告器，
+// 返回的函数在发送请求的响应之前调用以停止报告进度
+func withProgress(ctx context.Context, request jsonrpc.Request, reply func(interface{})) (context.Context, func()) {
+	var params struct {
+		Meta struct {
+			ProgressToken json.RawMessage `json:"progressToken"`
+		} `json:"_meta"`
+	}
+
+	if err := json.Unmarshal(request.Params, &params); err != nil || len(params.Meta.ProgressToken) == 0 {
+		return ctx, func() {}
+	}
+
+	reporter := &progressReporter{
+		ctx:   ctx,
+		token: params.Meta.ProgressToken,
+		reply: reply,
+	}
+	return tools.WithProgressReporter(ctx, reporter), reporter.stop
+}
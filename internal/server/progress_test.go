@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+func TestProgressStop(t *testing.T) {
+	var sent []ProgressParams
+	reply := func(message interface{}) {
+		notification := message.(jsonrpc.Notification)
+		sent = append(sent, notification.Params.(ProgressParams))
+	}
+
+	request := jsonrpc.Request{
+		JSONRPC: jsonrpc.Version,
+		ID:      json.RawMessage(`1`),
+		Method:  MethodToolsCall,
+		Params:  json.RawMessage(`{"name": "tool", "_meta": {"progressToken": "p"}}`),
+	}
+	ctx, stop := withProgress(context.Background(), request, reply)
+	progress := tools.ProgressFromContext(ctx)
+
+	progress.Report(1, 3, "first")
+	progress.Report(1, 3, "not increasing")
+	stop()
+	// 响应发送之后工具仍在报告进度，这些报告被丢弃
+	progress.Report(2, 3, "after the response")
+
+	if len(sent) != 1 || sent[0].Progress != 1 || string(sent[0].ProgressToken) != `"p"` {
+		t.Fatalf("sent %+v, want only the first report", sent)
+	}
+
+	// 没有progressToken的请求不报告进度
+	request.Params = json.RawMessage(`{"name": "tool"}`)
+	ctx, stop = withProgress(context.Background(), request, reply)
+	tools.ProgressFromContext(ctx).Report(1, 1, "ignored")
+	stop()
+	if len(sent) != 1 {
+		t.Fatalf("request without a progress token sent %+v", sent[1:])
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/droid/go-mcp/internal/prompts"
+	"github.com/droid/go-mcp/internal/resources"
+)
+
+// 提示词相关方法名
+const (
+	MethodPromptsList = "prompts/list"
+	MethodPromptsGet  = "prompts/get"
+)
+
+// PromptsCapability 提示词能力
+type PromptsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// GetPromptParams prompts/get请求参数
+type GetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// WithPrompts 为服务器启用提示词功能
+func WithPrompts(registry *prompts.Registry) Option {
+	return func(s *MCPServer) {
+		s.prompts = registry
+	}
+}
+
+// handlePromptRequest 处理提示词相关请求
+func (s *MCPServer) handlePromptRequest(ctx context.Context, request jsonrpc.Request) (interface{}, *jsonrpc.Error) {
+	if s.prompts == nil {
+		return nil, jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "Method not found", request.Method)
+	}
+
+	switch request.Method {
+	case MethodPromptsList:
+		return map[string]interface{}{"prompts": s.prompts.List()}, nil
+
+	case MethodPromptsGet:
+		var params GetPromptParams
+		if err := unmarshalParams(request.Params, &params); err != nil {
+			return nil, err
+		}
+
+		result, err := s.prompts.Get(ctx, params.Name, params.Arguments)
+		if err == nil {
+			return result, nil
+		}
+
+		var argErr *prompts.ArgumentError
+		switch {
+		case errors.Is(err, prompts.ErrNotFound):
+			return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Unknown prompt", params.Name)
+		case errors.As(err, &argErr):
+			return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Missing required argument", argErr.Argument)
+		case errors.Is(err, resources.ErrNotFound):
+			return nil, jsonrpc.NewError(jsonrpc.CodeResourceNotFound, "Resource not found", err.Error())
+		default:
+			return nil, jsonrpc.NewError(jsonrpc.CodeInternalError, "Failed to render prompt", err.Error())
+		}
+
+	default:
+		return nil, jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "Method not found", request.Method)
+	}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/droid/go-mcp/internal/resources"
+)
+
+// 资源相关方法名
+const (
+	MethodResourcesList          = "resources/list"
+	MethodResourcesRead          = "resources/read"
+	MethodResourcesTemplatesList = "resources/templates/list"
+	MethodResourcesSubscribe     = "resources/subscribe"
+	MethodResourcesUnsubscribe   = "resources/unsubscribe"
+
+	NotificationResourceUpdated     = "notifications/resources/updated"
+	NotificationResourceListChanged = "notifications/resources/list_changed"
+)
+
+// ResourcesCapability 资源能力
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// ReadResourceParams resources/read请求参数
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// SubscribeParams resources/subscribe和resources/unsubscribe请求参数
+type SubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// subscriptions 记录客户端订阅的资源URI
+type subscriptions struct {
+	mutex sync.RWMutex
+	uris  map[string]bool
+}
+
+// add 订阅资源
+func (ss *subscriptions) add(uri string) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	if ss.uris == nil {
+		ss.uris = make(map[string]bool)
+	}
+	ss.uris[uri] = true
+}
+
+// remove 取消订阅资源
+func (ss *subscriptions) remove(uri string) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	delete(ss.uris, uri)
+}
+
+// has 判断是否订阅了资源
+func (ss *subscriptions) has(uri string) bool {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+
+	return ss.uris[uri]
+}
+
+// WithResources 为服务器启用资源功能
+func WithResources(resMgr *resources.Manager) Option {
+	return func(s *MCPServer) {
+		s.resMgr = resMgr
+	}
+}
+
+// handleResourceRequest 处理资源相关请求
+func (s *MCPServer) handleResourceRequest(ctx context.Context, c *Client, request jsonrpc.Request) (interface{}, *jsonrpc.Error) {
+	if s.resMgr == nil {
+		return nil, jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "Method not found", request.Method)
+	}
+
+	switch request.Method {
+	case MethodResourcesList:
+		items, err := s.resMgr.ListResources(ctx)
+		if err != nil {
+			return nil, jsonrpc.NewError(jsonrpc.CodeInternalError, "Failed to list resources", err.Error())
+		}
+		return map[string]interface{}{"resources": items}, nil
+
+	case MethodResourcesTemplatesList:
+		templates, err := s.resMgr.ListResourceTemplates(ctx)
+		if err != nil {
+			return nil, jsonrpc.NewError(jsonrpc.CodeInternalError, "Failed to list resource templates", err.Error())
+		}
+		return map[string]interface{}{"resourceTemplates": templates}, nil
+
+	case MethodResourcesRead:
+		var params ReadResourceParams
+		if err := unmarshalParams(request.Params, &params); err != nil {
+			return nil, err
+		}
+		if params.URI == "" {
+			return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Invalid params", "uri is required")
+		}
+
+		contents, err := s.resMgr.ReadResource(ctx, params.URI)
+		if errors.Is(err, resources.ErrNotFound) {
+			return nil, jsonrpc.NewError(jsonrpc.CodeResourceNotFound, "Resource not found", map[string]string{"uri": params.URI})
+		}
+		if err != nil {
+			return nil, jsonrpc.NewError(jsonrpc.CodeInternalError, "Failed to read resource", err.Error())
+		}
+		return map[string]interface{}{"contents": contents}, nil
+
+	case MethodResourcesSubscribe:
+		var params SubscribeParams
+		if err := unmarshalParams(request.Params, &params); err != nil {
+			return nil, err
+		}
+		if params.URI == "" {
+			return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Invalid params", "uri is required")
+		}
+
+		// 只允许订阅存在的资源
+		if _, err := s.resMgr.ReadResource(ctx, params.URI); errors.Is(err, resources.ErrNotFound) {
+			return nil, jsonrpc.NewError(jsonrpc.CodeResourceNotFound, "Resource not found", map[string]string{"uri": params.URI})
+		}
+
+		c.subscriptions.add(params.URI)
+		log.Printf("Client %s subscribed to %s", c.ID, params.URI)
+		return struct{}{}, nil
+
+	case MethodResourcesUnsubscribe:
+		var params SubscribeParams
+		if err := unmarshalParams(request.Params, &params); err != nil {
+			return nil, err
+		}
+
+		c.subscriptions.remove(params.URI)
+		log.Printf("Client %s unsubscribed from %s", c.ID, params.URI)
+		return struct{}{}, nil
+
+	default:
+		return nil, jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "Method not found", request.Method)
+	}
+}
+
+// handleResourceChange 将资源变更推送给相关客户端：
+// 内容更新只通知订阅了该资源的客户端，列表变化通知所有完成握手的客户端
+func (s *MCPServer) handleResourceChange(change resources.Change) {
+	var notification jsonrpc.Notification
+	switch change.Kind {
+	case resources.ChangeUpdated:
+		notification = jsonrpc.NewNotification(NotificationResourceUpdated, map[string]string{"uri": change.URI})
+	case resources.ChangeListChanged:
+		notification = jsonrpc.NewNotification(NotificationResourceListChanged, nil)
+	default:
+		return
+	}
+
+	for _, client := range s.readyClients() {
+		if change.Kind == resources.ChangeUpdated && !client.subscriptions.has(change.URI) {
+			continue
+		}
+
+		if !client.trySend(notification) {
+			log.Printf("Dropping %s for %s: send queue full", notification.Method, client.ID)
+		}
+	}
+}
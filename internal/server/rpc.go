@@ -0,0 +1,369 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/droid/go-mcp/internal/tools"
+)
+
+// MCP方法名
+const (
+	MethodInitialize = "initialize"
+	MethodPing       = "ping"
+	MethodToolsList  = "tools/list"
+	MethodToolsCall  = "tools/call"
+
+	NotificationInitialized = "notifications/initialized"
+	NotificationCancelled   = "notifications/cancelled"
+
+	NotificationToolsListChanged = "notifications/tools/list_changed"
+)
+
+// CallToolParams tools/call请求参数
+type CallToolParams struct {
+	Name      string                 `json:"name"`
+	Arguments json.RawMessage        `json:"arguments,omitempty"`
+	Meta      map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// CancelledParams notifications/cancelled通知参数
+type CancelledParams struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+// CallToolResult tools/call响应结果
+type CallToolResult struct {
+	Content           []tools.Content `json:"content"`
+	StructuredContent interface{}     `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+
+	// Meta 错误结果携带的错误码和详情
+	Meta map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// handleRPCMessage 处理一条JSON-RPC消息或批量请求，并将响应写入客户端发送队列
+func (s *MCPServer) handleRPCMessage(ctx context.Context, c *Client, data []byte) {
+	if jsonrpc.IsBatch(data) {
+		s.handleRPCBatch(ctx, c, data)
+		return
+	}
+
+	request, rpcErr := jsonrpc.ParseRequest(data)
+	if rpcErr != nil {
+		log.Printf("Invalid JSON-RPC message from %s: %s", c.ID, rpcErr.Message)
+		c.send(jsonrpc.NewErrorResponse(request.ID, rpcErr))
+		return
+	}
+
+	s.dispatchRPC(ctx, c, request, c.send)
+}
+
+// dispatchRPC 处理已解析的JSON-RPC消息，请求的响应交给reply发送。
+// 工具调用在独立的goroutine中执行，不阻塞后续消息的读取
+func (s *MCPServer) dispatchRPC(ctx context.Context, c *Client, request jsonrpc.Request, reply func(interface{})) {
+	if request.IsNotification() {
+		s.handleNotification(c, request)
+		return
+	}
+
+	if request.Method != MethodToolsCall {
+		reply(s.respond(ctx, c, request))
+		return
+	}
+
+	// 在启动goroutine之前占用排队名额，排队的调用已满时立即拒绝
+	admitted, ok := c.pool.admit()
+	if !ok {
+		_, rpcErr := toolError(tools.ErrorResponse(c.pool.busyError()))
+		reply(jsonrpc.NewErrorResponse(request.ID, rpcErr))
+		return
+	}
+
+	callCtx, finish, ok := c.inflight.begin(ctx, request.ID)
+	if !ok {
+		admitted()
+		reply(jsonrpc.NewErrorResponse(request.ID,
+			jsonrpc.NewError(jsonrpc.CodeInvalidRequest, "Duplicate request id", nil)))
+		return
+	}
+
+	callCtx, stopProgress := withProgress(callCtx, request, reply)
+	callCtx, replied := withReplyGate(callCtx)
+
+	go func() {
+		defer admitted()
+		defer replied()
+		response := s.respond(callCtx, c, request)
+		stopProgress()
+
+		// 已被客户端取消的请求不再发送响应
+		if finish() {
+			log.Printf("Request %s from %s cancelled", requestKey(request.ID), c.ID)
+			return
+		}
+		reply(response)
+	}()
+}
+
+// replyGateKey context中响应已发送信号的键
+type replyGateKey struct{}
+
+// withReplyGate 返回携带响应已发送信号的context和发送响应后调用的函数，
+// 请求执行中产生的后续通知（如异步任务完成）据此排在响应之后
+func withReplyGate(ctx context.Context) (context.Context, func()) {
+	gate := make(chan struct{})
+	var once sync.Once
+	return context.WithValue(ctx, replyGateKey{}, gate), func() {
+		once.Do(func() { close(gate) })
+	}
+}
+
+// awaitReply 等待请求的响应发送完毕，context中没有响应信号时立即返回
+func awaitReply(ctx context.Context) {
+	if gate, ok := ctx.Value(replyGateKey{}).(chan struct{}); ok {
+		<-gate
+	}
+}
+
+// respond 执行请求并构建响应
+func (s *MCPServer) respond(ctx context.Context, c *Client, request jsonrpc.Request) jsonrpc.Response {
+	result, rpcErr := s.handleRequest(ctx, c, request)
+	if rpcErr != nil {
+		return jsonrpc.NewErrorResponse(request.ID, rpcErr)
+	}
+	return jsonrpc.NewResponse(request.ID, result)
+}
+
+// handleRequest 根据方法名分发JSON-RPC请求
+func (s *MCPServer) handleRequest(ctx context.Context, c *Client, request jsonrpc.Request) (interface{}, *jsonrpc.Error) {
+	switch request.Method {
+	case MethodInitialize:
+		var params InitializeParams
+		if err := unmarshalParams(request.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(c, params)
+
+	case MethodPing:
+		return struct{}{}, nil
+	}
+
+	// 除ping外，其余请求必须在initialize之后
+	if !c.session.initialized() {
+		return nil, jsonrpc.NewError(jsonrpc.CodeServerNotInitialized, "Server not initialized", request.Method)
+	}
+
+	switch request.Method {
+	case MethodToolsList:
+		return map[string]interface{}{
+			"tools": s.listTools(),
+		}, nil
+
+	case MethodToolsCall:
+		var params CallToolParams
+		if err := unmarshalParams(request.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.callTool(tools.WithCaller(ctx, c.caller()), c, request, params)
+
+	case MethodResourcesList, MethodResourcesRead, MethodResourcesTemplatesList,
+		MethodResourcesSubscribe, MethodResourcesUnsubscribe:
+		return s.handleResourceRequest(ctx, c, request)
+
+	case MethodPromptsList, MethodPromptsGet:
+		return s.handlePromptRequest(ctx, request)
+
+	case MethodJobsList, MethodJobsGet, MethodJobsCancel:
+		return s.handleJobRequest(c, request)
+
+	default:
+		return nil, jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "Method not found", request.Method)
+	}
+}
+
+// handleNotification 处理客户端通知，通知不需要响应
+func (s *MCPServer) handleNotification(c *Client, request jsonrpc.Request) {
+	switch request.Method {
+	case NotificationInitialized:
+		if !c.session.markReady() {
+			log.Printf("Unexpected initialized notification from %s", c.ID)
+			return
+		}
+		log.Printf("Client initialized: %s", c.ID)
+
+	case NotificationCancelled:
+		var params CancelledParams
+		if err := unmarshalParams(request.Params, &params); err != nil || len(params.RequestID) == 0 {
+			log.Printf("Invalid cancelled notification from %s", c.ID)
+			return
+		}
+
+		if c.inflight.cancel(params.RequestID) {
+			log.Printf("Cancelling request %s from %s: %s", requestKey(params.RequestID), c.ID, params.Reason)
+		}
+
+	default:
+		log.Printf("Received notification from %s: %s", c.ID, request.Method)
+	}
+}
+
+// initialize 处理initialize请求，完成版本协商
+func (s *MCPServer) initialize(c *Client, params InitializeParams) (interface{}, *jsonrpc.Error) {
+	if params.ProtocolVersion == "" {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Invalid params", "protocolVersion is required")
+	}
+
+	if !isSupportedProtocolVersion(params.ProtocolVersion) {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Unsupported protocol version", map[string]interface{}{
+			"requested": params.ProtocolVersion,
+			"supported": SupportedProtocolVersions,
+		})
+	}
+
+	if !c.session.begin(params) {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidRequest, "Session already initialized", nil)
+	}
+
+	log.Printf("Client %s initializing: %s %s (protocol %s)",
+		c.ID, params.ClientInfo.Name, params.ClientInfo.Version, params.ProtocolVersion)
+
+	return InitializeResult{
+		ProtocolVersion: params.ProtocolVersion,
+		Capabilities:    s.serverCapabilities(),
+		ServerInfo: Implementation{
+			Name:    ServerName,
+			Version: ServerVersion,
+		},
+	}, nil
+}
+
+// listTools 以MCP格式返回工具列表
+func (s *MCPServer) listTools() []map[string]interface{} {
+	schemas := s.toolMgr.GetToolsSchema()
+	tools := make([]map[string]interface{}, 0, len(schemas))
+
+	for _, schema := range schemas {
+		tool := map[string]interface{}{
+			"name":        schema["name"],
+			"description": schema["description"],
+			"inputSchema": schema["parameters"],
+		}
+		if outputSchema, ok := schema["outputSchema"]; ok {
+			tool["outputSchema"] = outputSchema
+		}
+		tools = append(tools, tool)
+	}
+
+	return tools
+}
+
+// callTool 执行tools/call请求
+func (s *MCPServer) callTool(ctx context.Context, c *Client, request jsonrpc.Request, params CallToolParams) (interface{}, *jsonrpc.Error) {
+	if params.Name == "" {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Invalid params", "name is required")
+	}
+
+	if !s.toolMgr.HasTool(params.Name) {
+		return toolError(tools.ErrorResponse(tools.NewError(tools.CodeUnknownTool,
+			fmt.Sprintf("Unknown tool: %s", params.Name),
+			map[string]interface{}{"tool": params.Name})))
+	}
+
+	arguments := params.Arguments
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+
+	toolRequest := ToolRequest{
+		ID:       idString(request.ID),
+		Tool:     params.Name,
+		Params:   arguments,
+		Metadata: params.Meta,
+	}
+
+	// 异步调用立即返回任务ID，结果在任务结束后以通知推送
+	if async, _ := params.Meta[MetaAsync].(bool); async {
+		return s.startClientJob(ctx, c, toolRequest), nil
+	}
+
+	// 在客户端的工作池中排队，等待期间请求可被取消
+	release, err := c.pool.acquire(ctx, params.Name)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInternalError, "Request cancelled", err.Error())
+	}
+	defer release()
+
+	return s.toolResult(params.Name, s.runTool(ctx, toolRequest))
+}
+
+// toolResult 将工具响应转换为tools/call结果
+func (s *MCPServer) toolResult(tool string, response tools.ToolResponse) (interface{}, *jsonrpc.Error) {
+	if response.Status != "success" {
+		return toolError(response)
+	}
+
+	// 声明了输出schema的工具以structuredContent返回结果
+	result, err := tools.AsResult(response.Content, s.toolMgr.HasOutputSchema(tool))
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInternalError, "Internal error", err.Error())
+	}
+
+	return CallToolResult{
+		Content:           result.Content,
+		StructuredContent: result.StructuredContent,
+	}, nil
+}
+
+// toolError 将失败的工具响应转换为MCP结果：调用本身无效（未知工具、参数错误、
+// 无权限）或服务器错误时返回JSON-RPC错误，工具执行失败和超时以isError结果返回，
+// 让模型看到错误并自行调整。两种形式都携带机器可读的错误码和详情
+func toolError(response tools.ToolResponse) (interface{}, *jsonrpc.Error) {
+	data := map[string]interface{}{"code": response.Code}
+	for key, value := range response.Details {
+		data[key] = value
+	}
+
+	switch response.Code {
+	case tools.CodeUnknownTool, tools.CodeInvalidParams:
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, response.Error, data)
+	case tools.CodePermissionDenied:
+		return nil, jsonrpc.NewError(jsonrpc.CodePermissionDenied, response.Error, data)
+	case tools.CodeBusy:
+		return nil, jsonrpc.NewError(jsonrpc.CodeServerBusy, response.Error, data)
+	case tools.CodeInvalidOutput, tools.CodeCancelled, tools.CodeInternalError, tools.CodeToolDisabled:
+		return nil, jsonrpc.NewError(jsonrpc.CodeInternalError, response.Error, data)
+	}
+
+	return CallToolResult{
+		Content: []tools.Content{tools.TextContent(response.Error)},
+		IsError: true,
+		Meta:    data,
+	}, nil
+}
+
+// unmarshalParams 解析请求参数，参数格式错误时返回InvalidParams
+func unmarshalParams(params json.RawMessage, v interface{}) *jsonrpc.Error {
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+
+	if err := json.Unmarshal(params, v); err != nil {
+		return jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Invalid params", err.Error())
+	}
+	return nil
+}
+
+// idString 将JSON-RPC ID转换为字符串形式
+func idString(id json.RawMessage) string {
+	var str string
+	if err := json.Unmarshal(id, &str); err == nil {
+		return str
+	}
+	return string(id)
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"sync"
+)
+
+// 服务器信息
+const (
+	ServerName        = "Go MCP Server"
+	ServerVersion     = "1.0.0"
+	ServerDescription = "一个Model Context Protocol服务器实现"
+)
+
+// LatestProtocolVersion 服务器支持的最新MCP协议版本
+const LatestProtocolVersion = "2025-06-18"
+
+// SupportedProtocolVersions 服务器支持的MCP协议版本
+var SupportedProtocolVersions = []string{
+	LatestProtocolVersion,
+	"2025-03-26",
+	"2024-11-05",
+}
+
+// 会话生命周期状态
+const (
+	sessionNew          = iota // 尚未收到initialize请求
+	sessionInitializing        // 已响应initialize，等待initialized通知
+	sessionReady               // 握手完成
+)
+
+// Implementation 描述客户端或服务器的实现信息
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// InitializeParams initialize请求参数
+type InitializeParams struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ClientInfo      Implementation         `json:"clientInfo"`
+}
+
+// ToolsCapability 工具能力
+type ToolsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// ServerCapabilities 服务器能力声明
+type ServerCapabilities struct {
+	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
+	Resources *ResourcesCapability `json:"resources,omitempty"`
+	Tools     *ToolsCapability     `json:"tools,omitempty"`
+
+	// Experimental 非标准扩展，jobs表示支持异步任务
+	Experimental map[string]interface{} `json:"experimental,omitempty"`
+}
+
+// InitializeResult initialize响应结果
+type InitializeResult struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      Implementation     `json:"serverInfo"`
+	Instructions    string             `json:"instructions,omitempty"`
+}
+
+// session 记录客户端的握手状态
+type session struct {
+	mutex              sync.RWMutex
+	state              int
+	protocolVersion    string
+	clientInfo         Implementation
+	clientCapabilities map[string]interface{}
+}
+
+// begin 记录initialize请求，返回false表示会话已初始化
+func (ss *session) begin(params InitializeParams) bool {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	if ss.state != sessionNew {
+		return false
+	}
+
+	ss.state = sessionInitializing
+	ss.protocolVersion = params.ProtocolVersion
+	ss.clientInfo = params.ClientInfo
+	ss.clientCapabilities = params.Capabilities
+	return true
+}
+
+// markReady 处理initialized通知
+func (ss *session) markReady() bool {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	if ss.state != sessionInitializing {
+		return false
+	}
+
+	ss.state = sessionReady
+	return true
+}
+
+// initialized 判断是否已收到initialize请求
+func (ss *session) initialized() bool {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	return ss.state != sessionNew
+}
+
+// ready 判断握手是否完成
+func (ss *session) ready() bool {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	return ss.state == sessionReady
+}
+
+// client 返回initialize时声明的客户端信息
+func (ss *session) client() Implementation {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	return ss.clientInfo
+}
+
+// isSupportedProtocolVersion 判断协议版本是否受支持
+func isSupportedProtocolVersion(version string) bool {
+	for _, v := range SupportedProtocolVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// serverCapabilities 返回服务器当前支持的能力
+func (s *MCPServer) serverCapabilities() ServerCapabilities {
+	// 服务器不向JSON-RPC客户端发送日志消息，不声明logging能力
+	capabilities := ServerCapabilities{
+		Tools: &ToolsCapability{ListChanged: true},
+		Experimental: map[string]interface{}{
+			"jobs": struct{}{},
+		},
+	}
+
+	if s.resMgr != nil {
+		capabilities.Resources = &ResourcesCapability{
+			Subscribe:   true,
+			ListChanged: true,
+		}
+	}
+
+	if s.prompts != nil {
+		capabilities.Prompts = &PromptsCapability{}
+	}
+
+	return capabilities
+}
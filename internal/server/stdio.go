@@ -0,0 +1,71 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+)
+
+// stdioClientID stdio传输下唯一客户端的ID
+const stdioClientID = "stdio"
+
+// ServeStdio 通过标准输入输出提供换行分隔的JSON-RPC服务，直到输入结束
+func (s *MCPServer) ServeStdio(in io.Reader, out io.Writer) error {
+	client := newClient(s, stdioClientID, ProtocolJSONRPC, TransportStdio, nil)
+
+	s.register <- client
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.writeLines(out)
+	}()
+
+	reader := bufio.NewReader(in)
+	var readErr error
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			s.handleRPCMessage(client.ctx, client, line)
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
+			break
+		}
+	}
+
+	// 等待已接收的请求执行完毕，再注销客户端关闭发送队列，等待剩余消息写出
+	client.inflight.wait()
+	s.unregister <- client
+	<-done
+
+	return readErr
+}
+
+// writeLines 将发送队列中的消息逐行写出
+func (c *Client) writeLines(out io.Writer) {
+	writer := bufio.NewWriter(out)
+
+	for message := range c.Send {
+		messageBytes, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("error encoding message: %v", err)
+			continue
+		}
+
+		messageBytes = append(messageBytes, '\n')
+		if _, err := writer.Write(messageBytes); err != nil {
+			log.Printf("error writing message: %v", err)
+			continue
+		}
+
+		if err := writer.Flush(); err != nil {
+			log.Printf("error flushing message: %v", err)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/droid/go-mcp/internal/jsonrpc"
+	"github.com/google/uuid"
+)
+
+// SessionHeader Streamable HTTP传输中携带会话ID的请求头
+const SessionHeader = "Mcp-Session-Id"
+
+// DefaultSessionTTL HTTP会话默认的空闲过期时间
+const DefaultSessionTTL = 30 * time.Minute
+
+// WithSessionTTL 设置HTTP会话的空闲过期时间，没有SSE流和执行中请求的会话
+// 空闲超过该时间后被注销；0表示会话只在DELETE时结束
+func WithSessionTTL(ttl time.Duration) Option {
+	return func(s *MCPServer) {
+		s.sessionTTL = ttl
+	}
+}
+
+// WithAllowedOrigins 设置允许访问Streamable HTTP端点的Origin，如 https://app.example.com，
+// "*" 表示允许任意Origin。未设置时只允许本机Origin（localhost、127.0.0.1、[::1]），
+// 防止DNS重绑定攻击中的网页访问本地服务器。没有Origin请求头的请求（非浏览器客户端）总是允许
+func WithAllowedOrigins(origins ...string) Option {
+	return func(s *MCPServer) {
+		s.allowedOrigins = append(s.allowedOrigins, origins...)
+	}
+}
+
+// originAllowed 检查请求的Origin是否允许访问Streamable HTTP端点
+func (s *MCPServer) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	if len(s.allowedOrigins) == 0 {
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		switch u.Hostname() {
+		case "localhost", "127.0.0.1", "::1":
+			return true
+		}
+		return false
+	}
+
+	for _, allowed := range s.allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionSweepInterval 返回检查过期会话的间隔
+func sessionSweepInterval(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < time.Minute {
+		return ttl
+	}
+	return time.Minute
+}
+
+// expireSessions 注销空闲过期的HTTP会话，由Run调用
+func (s *MCPServer) expireSessions() {
+	if s.sessionTTL <= 0 {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, client := range s.clients {
+		if client.Transport == TransportHTTP && client.idle(s.sessionTTL) {
+			delete(s.clients, id)
+			client.close()
+			log.Printf("Session expired: %s", id)
+		}
+	}
+}
+
+// HandleStreamableHTTP 处理Streamable HTTP传输：
+// POST发送JSON-RPC消息，GET建立服务器推送的SSE流，DELETE结束会话。
+// Origin不在允许列表中的请求返回403
+func (s *MCPServer) HandleStreamableHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.originAllowed(r) {
+		log.Printf("Rejected Streamable HTTP request from origin %s", r.Header.Get("Origin"))
+		http.Error(w, "Origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		s.handleHTTPPost(w, r)
+	case http.MethodGet:
+		s.handleHTTPStream(w, r)
+	case http.MethodDelete:
+		s.handleHTTPDelete(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleHTTPPost 处理客户端POST的JSON-RPC消息
+func (s *MCPServer) handleHTTPPost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	// 批量消息使用已有会话执行，响应数组以JSON返回
+	if jsonrpc.IsBatch(body) {
+		s.handleHTTPBatch(w, r, body)
+		return
+	}
+
+	request, rpcErr := jsonrpc.ParseRequest(body)
+	if rpcErr != nil {
+		writeJSON(w, http.StatusBadRequest, jsonrpc.NewErrorResponse(request.ID, rpcErr))
+		return
+	}
+
+	// initialize请求创建新会话，其余请求必须携带会话ID
+	var client *Client
+	newSession := request.Method == MethodInitialize && r.Header.Get(SessionHeader) == ""
+	if newSession {
+		client = newClient(s, uuid.New().String(), ProtocolJSONRPC, TransportHTTP, nil)
+		s.register <- client
+	} else {
+		var status int
+		client, status = s.httpSession(r)
+		if client == nil {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+	}
+	defer client.touch()
+
+	// 通知和响应不需要返回内容
+	if request.IsNotification() {
+		s.dispatchRPC(r.Context(), client, request, client.send)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// 请求的context随HTTP连接结束而取消
+	reply := make(chan interface{}, 16)
+	s.dispatchRPC(r.Context(), client, request, func(message interface{}) {
+		select {
+		case reply <- message:
+		case <-r.Context().Done():
+		}
+	})
+
+	// 工具调用可能产生中间通知，客户端接受时以SSE流返回
+	if request.Method == MethodToolsCall && acceptsEventStream(r) {
+		s.streamReply(w, r, client, reply)
+		return
+	}
+
+	// JSON响应无法携带中间通知，转发到会话的SSE流
+	response, ok := awaitResponse(r, reply, client.send)
+	if !ok {
+		// 客户端在收到initialize响应前断开，会话ID无从得知，不保留会话
+		if newSession {
+			s.unregister <- client
+		}
+		return
+	}
+
+	if newSession {
+		if response.Error != nil {
+			// 握手失败不保留会话
+			s.unregister <- client
+		} else {
+			w.Header().Set(SessionHeader, client.ID)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleHTTPBatch 处理POST的JSON-RPC批量消息。批量中只有通知时返回202，
+// 否则等待全部请求结束后返回响应数组，中间通知转发到会话的SSE流
+func (s *MCPServer) handleHTTPBatch(w http.ResponseWriter, r *http.Request, body []byte) {
+	messages, rpcErr := parseBatch(body)
+	if rpcErr != nil {
+		writeJSON(w, http.StatusBadRequest, jsonrpc.NewErrorResponse(nil, rpcErr))
+		return
+	}
+
+	client, status := s.httpSession(r)
+	if client == nil {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	defer client.touch()
+
+	results := make(chan []jsonrpc.Response, 1)
+	if !s.dispatchBatch(r.Context(), client, messages, client.send, func(responses []jsonrpc.Response) {
+		results <- responses
+	}) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	select {
+	case responses := <-results:
+		writeJSON(w, http.StatusOK, responses)
+	case <-r.Context().Done():
+	}
+}
+
+// streamReply 以SSE流返回请求的中间通知和最终响应
+func (s *MCPServer) streamReply(w http.ResponseWriter, r *http.Request, client *Client, reply <-chan interface{}) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	startEventStream(w)
+	flusher.Flush()
+
+	response, ok := awaitResponse(r, reply, func(message interface{}) {
+		if err := writeEvent(w, message); err != nil {
+			log.Printf("error writing event to %s: %v", client.ID, err)
+			return
+		}
+		flusher.Flush()
+	})
+	if !ok {
+		return
+	}
+
+	if err := writeEvent(w, response); err != nil {
+		log.Printf("error writing event to %s: %v", client.ID, err)
+		return
+	}
+	flusher.Flush()
+}
+
+// handleHTTPStream 建立SSE流，推送会话发送队列中的服务器消息
+func (s *MCPServer) handleHTTPStream(w http.ResponseWriter, r *http.Request) {
+	if !acceptsEventStream(r) {
+		http.Error(w, "Not acceptable", http.StatusNotAcceptable)
+		return
+	}
+
+	client, status := s.httpSession(r)
+	if client == nil {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// 流打开期间服务器消息才进入发送队列。每个会话只有一个SSE流，
+	// 多个流同时读取发送队列会使每条消息只到达其中一个
+	detach, ok := client.attachStream()
+	if !ok {
+		http.Error(w, "Session already has an open SSE stream", http.StatusConflict)
+		return
+	}
+	defer detach()
+
+	startEventStream(w)
+	flusher.Flush()
+
+	log.Printf("SSE stream opened: %s", client.ID)
+	defer log.Printf("SSE stream closed: %s", client.ID)
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message, ok := <-client.Send:
+			if !ok {
+				// 会话已结束
+				return
+			}
+
+			if err := writeEvent(w, message); err != nil {
+				log.Printf("error writing event to %s: %v", client.ID, err)
+				return
+			}
+			flusher.Flush()
+
+		case <-ticker.C:
+			// SSE注释行作为心跳，防止代理断开空闲连接
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleHTTPDelete 结束会话
+func (s *MCPServer) handleHTTPDelete(w http.ResponseWriter, r *http.Request) {
+	client, status := s.httpSession(r)
+	if client == nil {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	s.unregister <- client
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// httpSession 根据请求头查找HTTP会话，失败时返回对应的HTTP状态码
+func (s *MCPServer) httpSession(r *http.Request) (*Client, int) {
+	sessionID := r.Header.Get(SessionHeader)
+	if sessionID == "" {
+		return nil, http.StatusBadRequest
+	}
+
+	s.mutex.RLock()
+	client, exists := s.clients[sessionID]
+	s.mutex.RUnlock()
+
+	if !exists || client.Transport != TransportHTTP {
+		return nil, http.StatusNotFound
+	}
+
+	client.touch()
+	return client, http.StatusOK
+}
+
+// awaitResponse 等待请求的最终响应，期间收到的通知交给onNotify处理
+func awaitResponse(r *http.Request, reply <-chan interface{}, onNotify func(interface{})) (jsonrpc.Response, bool) {
+	for {
+		select {
+		case message := <-reply:
+			if response, ok := message.(jsonrpc.Response); ok {
+				return response, true
+			}
+			if onNotify != nil {
+				onNotify(message)
+			}
+
+		case <-r.Context().Done():
+			return jsonrpc.Response{}, false
+		}
+	}
+}
+
+// acceptsEventStream 判断客户端是否接受SSE响应
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// startEventStream 写入SSE响应头
+func startEventStream(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeEvent 写入一条SSE消息事件
+func writeEvent(w io.Writer, message interface{}) error {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: message\ndata: %s\n\n", messageBytes)
+	return err
+}
+
+// writeJSON 写入JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
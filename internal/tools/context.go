@@ -0,0 +1,26 @@
+package tools
+
+import (
+	"context"
+)
+
+// Caller 描述发起工具调用的客户端
+type Caller struct {
+	ID        string `json:"id"`
+	Transport string `json:"transport"`
+	Client    string `json:"client,omitempty"` // initialize时声明的客户端名称
+}
+
+// callerKey context中保存调用方信息的键
+type callerKey struct{}
+
+// WithCaller 返回携带调用方信息的context
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext 从context中获取调用方信息
+func CallerFromContext(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(Caller)
+	return caller, ok
+}
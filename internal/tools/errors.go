@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// 机器可读的错误码，客户端可据此分支处理和本地化错误信息
+const (
+	// CodeUnknownTool 工具不存在
+	CodeUnknownTool = "unknown_tool"
+	// CodeInvalidParams 参数不符合工具的ParameterSchema或工具的参数要求
+	CodeInvalidParams = "invalid_params"
+	// CodeInvalidOutput 结果不符合工具声明的输出schema
+	CodeInvalidOutput = "invalid_output"
+	// CodeExecutionFailed 工具执行失败
+	CodeExecutionFailed = "execution_failed"
+	// CodeTimeout 工具执行超时
+	CodeTimeout = "timeout"
+	// CodeCancelled 调用被取消
+	CodeCancelled = "cancelled"
+	// CodePermissionDenied 调用方无权执行该工具
+	CodePermissionDenied = "permission_denied"
+	// CodeInternalError 工具或中间件panic，详情中的incident_id对应服务器日志
+	CodeInternalError = "internal_error"
+	// CodeToolDisabled 工具因反复panic已被禁用
+	CodeToolDisabled = "tool_disabled"
+	// CodeBusy 客户端执行中和等待中的调用过多，稍后重试
+	CodeBusy = "busy"
+)
+
+// Error 带错误码和详情的工具错误，工具可以在Execute中返回以指定错误类别
+type Error struct {
+	Code    string
+	Message string
+	Details map[string]interface{}
+
+	// Err 原始错误
+	Err error
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError 创建工具错误
+func NewError(code, message string, details map[string]interface{}) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+// Errorf 创建带格式化信息的工具错误
+func Errorf(code, format string, args ...interface{}) *Error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// AsError 将任意错误归类为工具错误：*Error原样返回，context超时和取消
+// 分别归为timeout和cancelled，其余归为execution_failed
+func AsError(err error) *Error {
+	var toolErr *Error
+	if errors.As(err, &toolErr) {
+		return toolErr
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Code: CodeTimeout, Message: "Tool execution timed out", Err: err}
+	case errors.Is(err, context.Canceled):
+		return &Error{Code: CodeCancelled, Message: "Tool execution cancelled", Err: err}
+	default:
+		return &Error{Code: CodeExecutionFailed, Message: err.Error(), Err: err}
+	}
+}
+
+// ErrorResponse 将错误转换为工具响应
+func ErrorResponse(err error) ToolResponse {
+	toolErr := AsError(err)
+	return ToolResponse{
+		Status:  "error",
+		Error:   toolErr.Message,
+		Code:    toolErr.Code,
+		Details: toolErr.Details,
+	}
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Handler 处理一次工具调用，调用方信息可通过CallerFromContext获取
+type Handler func(ctx context.Context, request ToolRequest) ToolResponse
+
+// Middleware 包装Handler，在调用前后添加行为。中间件可以改写请求后调用next，
+// 不调用next直接返回响应（短路），或改写next返回的响应
+type Middleware func(next Handler) Handler
+
+// Use 按顺序注册中间件，先注册的中间件在外层，最先收到请求
+func (tm *ToolManager) Use(middleware ...Middleware) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	tm.middleware = append(tm.middleware, middleware...)
+}
+
+// chain 用已注册的中间件包装handler
+func (tm *ToolManager) chain(handler Handler) Handler {
+	tm.mutex.RLock()
+	middleware := make([]Middleware, len(tm.middleware))
+	copy(middleware, tm.middleware)
+	tm.mutex.RUnlock()
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// Logging 记录每次工具调用的调用方、耗时和结果的中间件
+func Logging() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request ToolRequest) ToolResponse {
+			start := time.Now()
+			response := next(ctx, request)
+
+			caller, _ := CallerFromContext(ctx)
+			if response.Status == "success" {
+				log.Printf("Tool %s called by %s (%s) succeeded in %s",
+					request.Name, caller.ID, caller.Transport, time.Since(start))
+			} else {
+				log.Printf("Tool %s called by %s (%s) failed in %s: %s: %s",
+					request.Name, caller.ID, caller.Transport, time.Since(start), response.Code, response.Error)
+			}
+			return response
+		}
+	}
+}
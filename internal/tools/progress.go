@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"context"
+)
+
+// ProgressReporter 报告工具执行进度
+type ProgressReporter interface {
+	// Report 报告当前进度，total未知时传0
+	Report(progress, total float64, message string)
+}
+
+// ProgressFunc 函数形式的ProgressReporter
+type ProgressFunc func(progress, total float64, message string)
+
+// Report 实现ProgressReporter接口
+func (f ProgressFunc) Report(progress, total float64, message string) {
+	f(progress, total, message)
+}
+
+// progressKey context中保存进度报告器的键
+type progressKey struct{}
+
+// WithProgressReporter 返回携带进度报告器的context
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressKey{}, reporter)
+}
+
+// ProgressFromContext 获取执行时传入的进度报告器，调用方未请求进度时返回空实现
+func ProgressFromContext(ctx context.Context) ProgressReporter {
+	if reporter, ok := ctx.Value(progressKey{}).(ProgressReporter); ok {
+		return reporter
+	}
+	return ProgressFunc(func(float64, float64, string) {})
+}
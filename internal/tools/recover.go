@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	"github.com/google/uuid"
+)
+
+// DefaultPanicThreshold 工具连续panic达到该次数后被自动禁用
+const DefaultPanicThreshold = 3
+
+// WithPanicThreshold 设置工具连续panic多少次后被自动禁用，0表示不自动禁用
+func WithPanicThreshold(n int) ManagerOption {
+	return func(tm *ToolManager) {
+		tm.panicThreshold = n
+	}
+}
+
+// invoke 执行工具并恢复panic：panic以带事件ID的internal_error返回，
+// 堆栈写入日志，连续panic次数达到阈值时禁用工具
+func (tm *ToolManager) invoke(ctx context.Context, name string, reg *registration, params json.RawMessage) (result interface{}, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			tm.recordSuccess(reg)
+			return
+		}
+
+		incidentID := uuid.New().String()
+		log.Printf("Tool %s panicked (incident %s): %v\n%s", name, incidentID, recovered, debug.Stack())
+
+		if tm.recordPanic(reg) {
+			log.Printf("Tool %s disabled after %d consecutive panics", name, tm.panicThreshold)
+			tm.notifyChange()
+		}
+
+		result = nil
+		err = NewError(CodeInternalError,
+			fmt.Sprintf("Internal error in tool '%s' (incident %s)", name, incidentID),
+			map[string]interface{}{"incident_id": incidentID})
+	}()
+
+	return reg.tool.ExecuteContext(ctx, params)
+}
+
+// recoverMiddleware 恢复中间件中的panic，返回带事件ID的internal_error响应
+func recoverMiddleware(name string, response *ToolResponse) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	incidentID := uuid.New().String()
+	log.Printf("Middleware panicked while calling tool %s (incident %s): %v\n%s", name, incidentID, recovered, debug.Stack())
+
+	*response = ErrorResponse(NewError(CodeInternalError,
+		fmt.Sprintf("Internal error while calling tool '%s' (incident %s)", name, incidentID),
+		map[string]interface{}{"incident_id": incidentID}))
+}
+
+// recordPanic 记录一次panic，返回工具是否因此被禁用
+func (tm *ToolManager) recordPanic(reg *registration) bool {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	reg.stats.Panics++
+	reg.consecutivePanics++
+
+	if tm.panicThreshold > 0 && !reg.disabled && reg.consecutivePanics >= tm.panicThreshold {
+		reg.disabled = true
+		return true
+	}
+	return false
+}
+
+// recordSuccess 工具正常返回时重置连续panic计数
+func (tm *ToolManager) recordSuccess(reg *registration) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	reg.consecutivePanics = 0
+}
+
+// isDisabled 判断工具是否已被禁用
+func (tm *ToolManager) isDisabled(reg *registration) bool {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	return reg.disabled
+}
+
+// EnableTool 重新启用被自动禁用的工具并重置连续panic计数，工具不存在时返回false
+func (tm *ToolManager) EnableTool(name string) bool {
+	tm.mutex.Lock()
+	reg, exists := tm.tools[name]
+	wasDisabled := exists && reg.disabled
+	if exists {
+		reg.disabled = false
+		reg.consecutivePanics = 0
+	}
+	tm.mutex.Unlock()
+
+	if wasDisabled {
+		log.Printf("Tool enabled: %s", name)
+		tm.notifyChange()
+	}
+	return exists
+}
@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"encoding/json"
+
+	"github.com/droid/go-mcp/internal/resources"
+)
+
+// 内容块类型
+const (
+	ContentText         = "text"
+	ContentResource     = "resource"
+	ContentResourceLink = "resource_link"
+	ContentImage        = "image"
+	ContentAudio        = "audio"
+)
+
+// Content 工具结果中的内容块，Type决定使用哪些字段
+type Content struct {
+	Type string `json:"type"`
+
+	// text
+	Text string `json:"text,omitempty"`
+
+	// resource：嵌入的资源内容
+	Resource *resources.Contents `json:"resource,omitempty"`
+
+	// image、audio：base64编码的数据，MimeType为其媒体类型
+	Data string `json:"data,omitempty"`
+
+	// resource_link：指向可通过resources/read读取的资源
+	URI         string `json:"uri,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// TextContent 创建文本内容块
+func TextContent(text string) Content {
+	return Content{Type: ContentText, Text: text}
+}
+
+// EmbeddedResource 创建嵌入资源内容的内容块
+func EmbeddedResource(contents resources.Contents) Content {
+	return Content{Type: ContentResource, Resource: &contents}
+}
+
+// ResourceLink 创建指向资源的内容块
+func ResourceLink(resource resources.Resource) Content {
+	return Content{
+		Type:        ContentResourceLink,
+		URI:         resource.URI,
+		Name:        resource.Name,
+		Description: resource.Description,
+		MimeType:    resource.MimeType,
+	}
+}
+
+// Result 工具的结构化结果，工具返回*Result时可完全控制返回给客户端的内容块
+type Result struct {
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+
+	// Plain 不使用内容块的协议（REST、旧版消息）返回的值，为nil时使用StructuredContent，
+	// 用于保持这些协议上已有的结果格式
+	Plain interface{} `json:"-"`
+}
+
+// NewResult 创建由内容块组成的结果
+func NewResult(content ...Content) *Result {
+	if content == nil {
+		content = []Content{}
+	}
+	return &Result{Content: content}
+}
+
+// StructuredResult 创建以value作为structuredContent的结果，
+// 同时附带value的JSON文本，兼容不支持structuredContent的客户端
+func StructuredResult(value interface{}, content ...Content) (*Result, error) {
+	text, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Content:           append([]Content{TextContent(string(text))}, content...),
+		StructuredContent: value,
+	}, nil
+}
+
+// AsResult 将工具的返回值转换为Result：*Result原样使用；structured为true时
+// 返回值作为structuredContent；其他返回值转换为文本内容块
+func AsResult(value interface{}, structured bool) (*Result, error) {
+	switch v := value.(type) {
+	case *Result:
+		if v == nil {
+			return NewResult(), nil
+		}
+		if v.Content == nil && v.StructuredContent != nil {
+			result, err := StructuredResult(v.StructuredContent)
+			if err != nil {
+				return nil, err
+			}
+			result.Plain = v.Plain
+			return result, nil
+		}
+		if v.Content == nil {
+			v.Content = []Content{}
+		}
+		return v, nil
+
+	case Result:
+		return AsResult(&v, structured)
+
+	case string:
+		if !structured {
+			return NewResult(TextContent(v)), nil
+		}
+	}
+
+	if structured {
+		return StructuredResult(value)
+	}
+
+	text, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return NewResult(TextContent(string(text))), nil
+}
+
+// PlainValue 返回不使用内容块的协议（REST、旧版消息）返回的结果：*Result取其Plain或
+// structuredContent，都没有时只有一个文本块取其文本、否则取内容块列表；
+// 其他值原样返回
+func PlainValue(value interface{}) interface{} {
+	var result *Result
+	switch v := value.(type) {
+	case *Result:
+		result = v
+	case Result:
+		result = &v
+	default:
+		return value
+	}
+
+	switch {
+	case result == nil:
+		return nil
+	case result.Plain != nil:
+		return result.Plain
+	case result.StructuredContent != nil:
+		return result.StructuredContent
+	case len(result.Content) == 1 && result.Content[0].Type == ContentText:
+		return result.Content[0].Text
+	default:
+		return result.Content
+	}
+}
+
+// structuredValue 返回用于校验输出schema的值
+func structuredValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *Result:
+		return v.StructuredContent
+	case Result:
+		return v.StructuredContent
+	default:
+		return value
+	}
+}
@@ -0,0 +1,38 @@
+package tools
+
+// ToolStats 工具的调用统计，从工具注册时开始计数
+type ToolStats struct {
+	Calls    int64 `json:"calls"`
+	Failures int64 `json:"failures"`
+	Timeouts int64 `json:"timeouts"`
+	Panics   int64 `json:"panics"`
+	Disabled bool  `json:"disabled"`
+}
+
+// recordCall 记录一次工具执行的结果
+func (tm *ToolManager) recordCall(reg *registration, response ToolResponse) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	reg.stats.Calls++
+	if response.Status != "success" {
+		reg.stats.Failures++
+	}
+	if response.Code == CodeTimeout {
+		reg.stats.Timeouts++
+	}
+}
+
+// Stats 返回所有已注册工具的调用统计
+func (tm *ToolManager) Stats() map[string]ToolStats {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	stats := make(map[string]ToolStats, len(tm.tools))
+	for name, reg := range tm.tools {
+		toolStats := reg.stats
+		toolStats.Disabled = reg.disabled
+		stats[name] = toolStats
+	}
+	return stats
+}
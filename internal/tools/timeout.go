@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 默认超时策略
+const (
+	// DefaultTimeout 未单独配置的工具的执行超时时间
+	DefaultTimeout = 60 * time.Second
+	// DefaultMaxTimeout 单次调用允许的最长超时时间，请求元数据中的超时不能超过该值
+	DefaultMaxTimeout = 5 * time.Minute
+)
+
+// TimeoutMetaKey 请求元数据中覆盖本次调用超时时间的键，单位毫秒
+const TimeoutMetaKey = "timeoutMs"
+
+// TimeoutTool 由需要不同于默认超时时间的工具实现
+type TimeoutTool interface {
+	// Timeout 返回工具的默认超时时间，0表示使用管理器的默认值
+	Timeout() time.Duration
+}
+
+// WithDefaultTimeout 设置工具的默认超时时间，0表示不限制
+func WithDefaultTimeout(d time.Duration) ManagerOption {
+	return func(tm *ToolManager) {
+		tm.defaultTimeout = d
+	}
+}
+
+// WithMaxTimeout 设置单次调用的超时上限，工具声明和请求元数据中的超时都不能超过该值；0表示不限制
+func WithMaxTimeout(d time.Duration) ManagerOption {
+	return func(tm *ToolManager) {
+		tm.maxTimeout = d
+	}
+}
+
+// WithToolTimeout 设置指定工具的超时时间，优先于工具自身声明的超时
+func WithToolTimeout(tool string, d time.Duration) ManagerOption {
+	return func(tm *ToolManager) {
+		tm.toolTimeouts[tool] = d
+	}
+}
+
+// timeoutOf 返回工具声明的超时时间
+func timeoutOf(tool ContextTool) time.Duration {
+	if declared, ok := underlying(tool).(TimeoutTool); ok {
+		return declared.Timeout()
+	}
+	return 0
+}
+
+// timeoutFor 计算本次调用的超时时间：请求元数据 > 管理器按工具配置 > 工具声明 > 默认值，
+// 结果不超过服务器的超时上限；0表示不限制
+func (tm *ToolManager) timeoutFor(request ToolRequest, reg *registration) time.Duration {
+	timeout := tm.defaultTimeout
+	if reg.timeout > 0 {
+		timeout = reg.timeout
+	}
+	if configured, ok := tm.toolTimeouts[request.Name]; ok {
+		timeout = configured
+	}
+	if requested, ok := requestedTimeout(request.Metadata); ok {
+		timeout = requested
+	}
+
+	if tm.maxTimeout > 0 && (timeout <= 0 || timeout > tm.maxTimeout) {
+		timeout = tm.maxTimeout
+	}
+	return timeout
+}
+
+// requestedTimeout 从请求元数据中读取超时时间
+func requestedTimeout(metadata map[string]interface{}) (time.Duration, bool) {
+	var ms float64
+	switch v := metadata[TimeoutMetaKey].(type) {
+	case float64:
+		ms = v
+	case int:
+		ms = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, false
+		}
+		ms = f
+	default:
+		return 0, false
+	}
+
+	if ms <= 0 {
+		return 0, false
+	}
+	return time.Duration(ms * float64(time.Millisecond)), true
+}
+
+// run 在超时时间内执行工具。工具在独立的goroutine中运行，超时或调用被取消时
+// 立即返回；不检查ctx的工具会在后台继续运行直到结束，其结果被丢弃
+func (tm *ToolManager) run(ctx context.Context, request ToolRequest, reg *registration, params json.RawMessage) ToolResponse {
+	timeout := tm.timeoutFor(request, reg)
+	callCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	type outcome struct {
+		result interface{}
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := tm.invoke(callCtx, request.Name, reg, params)
+		done <- outcome{result, err}
+	}()
+
+	var result outcome
+	select {
+	case result = <-done:
+	case <-callCtx.Done():
+		result = outcome{err: callCtx.Err()}
+	}
+
+	// 由本次调用的超时触发（而不是调用方取消）时返回带超时时间的错误
+	if result.err != nil && errors.Is(result.err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return ErrorResponse(&Error{
+			Code:    CodeTimeout,
+			Message: fmt.Sprintf("Tool '%s' timed out after %s", request.Name, timeout),
+			Details: map[string]interface{}{"timeout_ms": timeout.Milliseconds()},
+			Err:     result.err,
+		})
+	}
+
+	if result.err != nil {
+		return ErrorResponse(result.err)
+	}
+
+	return ToolResponse{
+		Status:  "success",
+		Content: result.result,
+	}
+}
@@ -1,22 +1,31 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/droid/go-mcp/internal/schema"
 )
 
 // ToolRequest 代表一个工具请求
 type ToolRequest struct {
-	Name       string          `json:"name"`
-	Parameters json.RawMessage `json:"parameters"`
+	Name       string                 `json:"name"`
+	Parameters json.RawMessage        `json:"parameters"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // ToolResponse 代表一个工具响应
 type ToolResponse struct {
-	Status  string      `json:"status"`
-	Content interface{} `json:"content,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Status  string                 `json:"status"`
+	Content interface{}            `json:"content,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+	Code    string                 `json:"code,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
 }
 
 // Tool 定义MCP工具接口
@@ -34,53 +43,354 @@ type Tool interface {
 	Execute(params json.RawMessage) (interface{}, error)
 }
 
-// ToolManager 管理MCP工具
+// ContextTool 定义支持context的MCP工具接口，
+// context在客户端断开、请求取消或超时时被取消，并携带调用方等请求信息
+type ContextTool interface {
+	// Name 返回工具名称
+	Name() string
+
+	// Description 返回工具描述
+	Description() string
+
+	// ParameterSchema 返回参数JSON Schema
+	ParameterSchema() string
+
+	// ExecuteContext 执行工具并返回结果
+	ExecuteContext(ctx context.Context, params json.RawMessage) (interface{}, error)
+}
+
+// OutputSchemaTool 由声明输出schema的工具实现，返回空字符串表示不声明。
+// 声明输出schema的工具结果以structuredContent返回，schema应描述一个对象
+type OutputSchemaTool interface {
+	// OutputSchema 返回结果JSON Schema
+	OutputSchema() string
+}
+
+// AdaptTool 将Tool适配为ContextTool，已实现ContextTool的工具直接返回
+func AdaptTool(tool Tool) ContextTool {
+	if contextTool, ok := tool.(ContextTool); ok {
+		return contextTool
+	}
+	return contextAdapter{Tool: tool}
+}
+
+// contextAdapter 为不支持context的工具提供ContextTool实现
+type contextAdapter struct {
+	Tool
+}
+
+// ExecuteContext 实现ContextTool接口，执行前检查context是否已取消
+func (a contextAdapter) ExecuteContext(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.Execute(params)
+}
+
+// registration 已注册的工具及其解析后的参数schema和输出schema
+type registration struct {
+	tool         ContextTool
+	schema       *schema.Schema // 解析失败时为nil，不做参数校验
+	outputSchema *schema.Schema // 未声明时为nil
+
+	// 超时时间，由工具通过TimeoutTool声明，0表示使用管理器的默认值
+	timeout time.Duration
+
+	// 调用统计，由ToolManager.mutex保护
+	stats             ToolStats
+	consecutivePanics int
+	disabled          bool
+}
+
+// ToolManager 管理MCP工具，支持在运行时并发地注册和注销工具
 type ToolManager struct {
-	tools map[string]Tool
+	tools      map[string]*registration
+	listeners  []func()
+	middleware []Middleware
+	mutex      sync.RWMutex
+
+	// 连续panic达到该次数的工具被自动禁用
+	panicThreshold int
+
+	// 工具执行超时策略
+	defaultTimeout time.Duration
+	maxTimeout     time.Duration
+	toolTimeouts   map[string]time.Duration
 }
 
+// ManagerOption 配置工具管理器
+type ManagerOption func(*ToolManager)
+
 // NewToolManager 创建新的工具管理器
-func NewToolManager() *ToolManager {
-	return &ToolManager{
-		tools: make(map[string]Tool),
+func NewToolManager(opts ...ManagerOption) *ToolManager {
+	tm := &ToolManager{
+		tools:          make(map[string]*registration),
+		panicThreshold: DefaultPanicThreshold,
+		defaultTimeout: DefaultTimeout,
+		maxTimeout:     DefaultMaxTimeout,
+		toolTimeouts:   make(map[string]time.Duration),
+	}
+
+	for _, opt := range opts {
+		opt(tm)
 	}
+
+	return tm
 }
 
 // RegisterTool 注册一个工具
 func (tm *ToolManager) RegisterTool(tool Tool) {
-	tm.tools[tool.Name()] = tool
+	tm.RegisterContextTool(AdaptTool(tool))
+}
+
+// RegisterContextTool 注册一个支持context的工具，同名工具会被替换
+func (tm *ToolManager) RegisterContextTool(tool ContextTool) {
+	tm.register(tool)
+	tm.notifyChange()
+}
+
+// RegisterContextTools 注册多个工具，全部注册后只通知一次工具列表变化
+func (tm *ToolManager) RegisterContextTools(tools ...ContextTool) {
+	tm.UpdateTools(tools, nil)
+}
+
+// UpdateTools 注册tools中的工具并注销names中的工具，有变化时只通知一次工具列表变化，
+// 用于重新加载一组工具
+func (tm *ToolManager) UpdateTools(tools []ContextTool, names []string) {
+	for _, tool := range tools {
+		tm.register(tool)
+	}
+	removed := tm.unregister(names)
+	if len(tools) > 0 || removed > 0 {
+		tm.notifyChange()
+	}
+}
+
+// register 解析工具的schema并保存，不通知监听函数
+func (tm *ToolManager) register(tool ContextTool) {
+	paramSchema, err := schema.Parse(tool.ParameterSchema())
+	if err != nil {
+		log.Printf("Error parsing schema for tool %s, parameters will not be validated: %v", tool.Name(), err)
+	}
+
+	var outputSchema *schema.Schema
+	if declared := outputSchemaOf(tool); declared != "" {
+		if outputSchema, err = schema.Parse(declared); err != nil {
+			log.Printf("Error parsing output schema for tool %s, output schema ignored: %v", tool.Name(), err)
+		}
+	}
+
+	tm.mutex.Lock()
+	tm.tools[tool.Name()] = &registration{
+		tool:         tool,
+		schema:       paramSchema,
+		outputSchema: outputSchema,
+		timeout:      timeoutOf(tool),
+	}
+	tm.mutex.Unlock()
+
 	log.Printf("Tool registered: %s", tool.Name())
 }
 
-// ExecuteTool 执行工具请求
-func (tm *ToolManager) ExecuteTool(request ToolRequest) ToolResponse {
-	tool, exists := tm.tools[request.Name]
-	if !exists {
-		return ToolResponse{
-			Status: "error",
-			Error:  fmt.Sprintf("Tool '%s' not found", request.Name),
+// UnregisterTool 注销一个工具，工具不存在时返回false
+func (tm *ToolManager) UnregisterTool(name string) bool {
+	return tm.UnregisterTools(name) > 0
+}
+
+// UnregisterTools 注销多个工具，返回实际注销的数量；有工具被注销时只通知一次工具列表变化
+func (tm *ToolManager) UnregisterTools(names ...string) int {
+	removed := tm.unregister(names)
+	if removed > 0 {
+		tm.notifyChange()
+	}
+	return removed
+}
+
+// unregister 注销工具并返回实际注销的数量，不通知监听函数
+func (tm *ToolManager) unregister(names []string) int {
+	var removed []string
+
+	tm.mutex.Lock()
+	for _, name := range names {
+		if _, exists := tm.tools[name]; exists {
+			delete(tm.tools, name)
+			removed = append(removed, name)
 		}
 	}
+	tm.mutex.Unlock()
 
-	result, err := tool.Execute(request.Parameters)
-	if err != nil {
-		return ToolResponse{
-			Status: "error",
-			Error:  err.Error(),
+	for _, name := range removed {
+		log.Printf("Tool unregistered: %s", name)
+	}
+	return len(removed)
+}
+
+// OnChange 注册工具列表变化的监听函数
+func (tm *ToolManager) OnChange(listener func()) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	tm.listeners = append(tm.listeners, listener)
+}
+
+// notifyChange 通知所有监听函数工具列表已变化
+func (tm *ToolManager) notifyChange() {
+	tm.mutex.RLock()
+	listeners := make([]func(), len(tm.listeners))
+	copy(listeners, tm.listeners)
+	tm.mutex.RUnlock()
+
+	for _, listener := range listeners {
+		listener()
+	}
+}
+
+// getTool 按名称查找工具
+func (tm *ToolManager) getTool(name string) (*registration, bool) {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	reg, exists := tm.tools[name]
+	return reg, exists
+}
+
+// underlying 返回适配器包装前的原工具，用于检查工具实现的可选接口
+func underlying(tool ContextTool) interface{} {
+	if adapter, ok := tool.(contextAdapter); ok {
+		return adapter.Tool
+	}
+	return tool
+}
+
+// outputSchemaOf 返回工具声明的输出schema
+func outputSchemaOf(tool ContextTool) string {
+	if declared, ok := underlying(tool).(OutputSchemaTool); ok {
+		return declared.OutputSchema()
+	}
+	return ""
+}
+
+// snapshot 返回按名称排序的工具列表，不包含已禁用的工具
+func (tm *ToolManager) snapshot() []*registration {
+	tm.mutex.RLock()
+	regs := make([]*registration, 0, len(tm.tools))
+	for _, reg := range tm.tools {
+		if !reg.disabled {
+			regs = append(regs, reg)
 		}
 	}
+	tm.mutex.RUnlock()
+
+	sort.Slice(regs, func(i, j int) bool {
+		return regs[i].tool.Name() < regs[j].tool.Name()
+	})
+	return regs
+}
+
+// HasTool 判断工具是否已注册
+func (tm *ToolManager) HasTool(name string) bool {
+	_, exists := tm.getTool(name)
+	return exists
+}
+
+// HasOutputSchema 判断工具是否声明了输出schema
+func (tm *ToolManager) HasOutputSchema(name string) bool {
+	reg, exists := tm.getTool(name)
+	return exists && reg.outputSchema != nil
+}
 
-	return ToolResponse{
-		Status:  "success",
-		Content: result,
+// ValidateOutput 按工具声明的输出schema校验结果，未声明输出schema时不做校验
+func (tm *ToolManager) ValidateOutput(name string, result interface{}) ([]schema.Violation, error) {
+	reg, exists := tm.getTool(name)
+	if !exists || reg.outputSchema == nil {
+		return nil, nil
 	}
+
+	data, err := json.Marshal(structuredValue(result))
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := schema.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return reg.outputSchema.Validate(value), nil
 }
 
-// GetToolsSchema 获取所有工具的JSON Schema
+// ExecuteTool 经过已注册的中间件执行工具请求，参数先按工具的ParameterSchema
+// 校验并填充默认值；ctx取消时工具应尽快返回。失败时响应的Code为机器可读的错误码
+func (tm *ToolManager) ExecuteTool(ctx context.Context, request ToolRequest) (response ToolResponse) {
+	defer recoverMiddleware(request.Name, &response)
+
+	return tm.chain(tm.execute)(ctx, request)
+}
+
+// execute 校验参数并执行工具，是中间件链的最内层
+func (tm *ToolManager) execute(ctx context.Context, request ToolRequest) ToolResponse {
+	reg, exists := tm.getTool(request.Name)
+	if !exists {
+		return ErrorResponse(NewError(CodeUnknownTool,
+			fmt.Sprintf("Tool '%s' not found", request.Name),
+			map[string]interface{}{"tool": request.Name}))
+	}
+
+	if tm.isDisabled(reg) {
+		return ErrorResponse(NewError(CodeToolDisabled,
+			fmt.Sprintf("Tool '%s' has been disabled after repeated internal errors", request.Name),
+			map[string]interface{}{"tool": request.Name}))
+	}
+
+	params, violations, err := reg.prepareParams(request.Parameters)
+	if err != nil {
+		return ErrorResponse(NewError(CodeInvalidParams,
+			fmt.Sprintf("Invalid parameters for tool '%s': %v", request.Name, err), nil))
+	}
+	if len(violations) > 0 {
+		return ErrorResponse(NewError(CodeInvalidParams,
+			fmt.Sprintf("Invalid parameters for tool '%s': %d violation(s)", request.Name, len(violations)),
+			map[string]interface{}{"violations": violations}))
+	}
+
+	response := tm.run(ctx, request, reg, params)
+	tm.recordCall(reg, response)
+	return response
+}
+
+// prepareParams 校验参数并填充默认值，返回处理后的参数
+func (reg *registration) prepareParams(params json.RawMessage) (json.RawMessage, []schema.Violation, error) {
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+
+	if reg.schema == nil {
+		return params, nil, nil
+	}
+
+	value, err := schema.Decode(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if violations := reg.schema.Validate(value); len(violations) > 0 {
+		return nil, violations, nil
+	}
+
+	prepared, err := json.Marshal(reg.schema.ApplyDefaults(value))
+	if err != nil {
+		return nil, nil, err
+	}
+	return prepared, nil, nil
+}
+
+// GetToolsSchema 获取所有工具的JSON Schema，声明了输出schema的工具包含outputSchema
 func (tm *ToolManager) GetToolsSchema() []map[string]interface{} {
-	schemas := make([]map[string]interface{}, 0, len(tm.tools))
+	regs := tm.snapshot()
+	schemas := make([]map[string]interface{}, 0, len(regs))
+
+	for _, reg := range regs {
+		tool := reg.tool
 
-	for _, tool := range tm.tools {
 		var schema map[string]interface{}
 		if err := json.Unmarshal([]byte(tool.ParameterSchema()), &schema); err != nil {
 			log.Printf("Error parsing schema for tool %s: %v", tool.Name(), err)
@@ -93,6 +403,16 @@ func (tm *ToolManager) GetToolsSchema() []map[string]interface{} {
 			"parameters":  schema,
 		}
 
+		// 解析后的schema只用于校验，列表中返回工具声明的原始schema以保留未建模的关键字
+		if reg.outputSchema != nil {
+			var outputSchema map[string]interface{}
+			if err := json.Unmarshal([]byte(outputSchemaOf(tool)), &outputSchema); err != nil {
+				log.Printf("Error parsing output schema for tool %s: %v", tool.Name(), err)
+			} else {
+				toolSchema["outputSchema"] = outputSchema
+			}
+		}
+
 		schemas = append(schemas, toolSchema)
 	}
 
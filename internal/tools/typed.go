@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/droid/go-mcp/internal/schema"
+)
+
+// TypedFunc 以类型化参数和结果实现的工具函数
+type TypedFunc[P any, R any] func(ctx context.Context, params P) (R, error)
+
+// TypedTool 由TypedFunc实现的ContextTool，参数schema由参数类型P生成；
+// 结果类型R为结构体时，同时生成输出schema
+type TypedTool[P any, R any] struct {
+	name         string
+	description  string
+	schema       string
+	outputSchema string
+	fn           TypedFunc[P, R]
+}
+
+// resultType Result的类型，返回Result的工具自行决定结构化内容，不生成输出schema
+var resultType = reflect.TypeOf(Result{})
+
+// NewTypedTool 创建类型化工具，参数类型P的字段标签见schema.Reflect；
+// P无法生成schema时panic
+func NewTypedTool[P any, R any](name, description string, fn TypedFunc[P, R]) *TypedTool[P, R] {
+	var params P
+	tool := &TypedTool[P, R]{
+		name:        name,
+		description: description,
+		schema:      schema.MustReflect(params).String(),
+		fn:          fn,
+	}
+
+	resultOf := reflect.TypeOf((*R)(nil)).Elem()
+	for resultOf.Kind() == reflect.Ptr {
+		resultOf = resultOf.Elem()
+	}
+	if resultOf.Kind() == reflect.Struct && resultOf != resultType {
+		tool.outputSchema = schema.MustReflect(reflect.New(resultOf).Elem().Interface()).String()
+	}
+
+	return tool
+}
+
+// Name 实现ContextTool接口
+func (t *TypedTool[P, R]) Name() string {
+	return t.name
+}
+
+// Description 实现ContextTool接口
+func (t *TypedTool[P, R]) Description() string {
+	return t.description
+}
+
+// ParameterSchema 实现ContextTool接口
+func (t *TypedTool[P, R]) ParameterSchema() string {
+	return t.schema
+}
+
+// OutputSchema 实现OutputSchemaTool接口
+func (t *TypedTool[P, R]) OutputSchema() string {
+	return t.outputSchema
+}
+
+// ExecuteContext 实现ContextTool接口，将参数解码为P后调用工具函数
+func (t *TypedTool[P, R]) ExecuteContext(ctx context.Context, paramsJSON json.RawMessage) (interface{}, error) {
+	var params P
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		return nil, Errorf(CodeInvalidParams, "Invalid parameters for tool %s: %v", t.name, err)
+	}
+
+	return t.fn(ctx, params)
+}